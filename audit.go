@@ -0,0 +1,19 @@
+package main
+
+import "strings"
+
+// auditSuffixes are the suffixes of audit and history tables recording the
+// changes of a base table, e.g. users_audit or orders_history.
+var auditSuffixes = []string{"_audit", "_history"}
+
+// auditBaseTable returns the base table an audit or history table belongs to,
+// when that table is generated too.
+func auditBaseTable(tableName string, generated map[string]bool) (string, bool) {
+	for _, suffix := range auditSuffixes {
+		base := strings.TrimSuffix(tableName, suffix)
+		if base != tableName && generated[base] {
+			return base, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,85 @@
+package main
+
+var currentUserTemplate = `package models
+
+import "context"
+
+// CurrentUserID returns the ID of the user on whose behalf a statement runs,
+// read from the statement's context. The generated BeforeCreate and
+// BeforeUpdate hooks use it to fill in the {{.Columns}} columns; set it
+// at startup, e.g.
+//
+//	models.CurrentUserID = func(ctx context.Context) ({{.Type}}, bool) {
+//	    userID, ok := ctx.Value(userIDKey{}).({{.Type}})
+//	    return userID, ok
+//	}
+//
+// and run statements with db.WithContext(ctx).
+var CurrentUserID func(ctx context.Context) ({{.Type}}, bool)
+`
+
+// AuditUser lists the created-by and updated-by columns of a table.
+type AuditUser struct {
+	CreatedBy []string
+	UpdatedBy []string
+	Hooks     bool
+}
+
+// CurrentUser is the data of the generated currentuser.go file.
+type CurrentUser struct {
+	Type    string
+	Columns string
+}
+
+// auditUserColumns returns the created-by and updated-by columns of a table,
+// or nil when it has none. Their fields are documented as filled in by the
+// hooks when those are enabled.
+func auditUserColumns(config Config, tableName string, columns []Column) *AuditUser {
+	auditUser := AuditUser{Hooks: config.AuditUserHooks}
+	for i, column := range columns {
+		switch {
+		case matchColumn(config.CreatedByColumns, tableName, column.GormName):
+			auditUser.CreatedBy = append(auditUser.CreatedBy, column.GormName)
+		case matchColumn(config.UpdatedByColumns, tableName, column.GormName):
+			auditUser.UpdatedBy = append(auditUser.UpdatedBy, column.GormName)
+		default:
+			continue
+		}
+		if config.AuditUserHooks && columns[i].Comment == "" {
+			columns[i].Comment = "Set to the current user by the generated hooks, see CurrentUserID"
+		}
+	}
+	if len(auditUser.CreatedBy) == 0 && len(auditUser.UpdatedBy) == 0 {
+		return nil
+	}
+	return &auditUser
+}
+
+// currentUserType returns the type of user IDs: the type of the first
+// created-by or updated-by column of the generated tables.
+func currentUserType(tables []Table) (string, bool) {
+	for _, table := range tables {
+		if table.AuditUser == nil {
+			continue
+		}
+		for _, column := range table.Columns {
+			if column.GormName != firstString(table.AuditUser.CreatedBy, table.AuditUser.UpdatedBy) {
+				continue
+			}
+			if valueType, ok := queryValueType(column.Type); ok && valueType != "time.Time" {
+				return valueType, true
+			}
+			return "interface{}", true
+		}
+	}
+	return "", false
+}
+
+func firstString(lists ...[]string) string {
+	for _, list := range lists {
+		if len(list) > 0 {
+			return list[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	avroNamePattern      = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	avroDecimalPattern   = regexp.MustCompile(`(?i)^(?:decimal|numeric)\((\d+)(?:,\s*(\d+))?\)`)
+	avroPrecisionPattern = regexp.MustCompile(`\((\d)\)`)
+)
+
+// AvroSchema is an Avro record schema of a table.
+type AvroSchema struct {
+	Type      string      `json:"type"`
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace,omitempty"`
+	Doc       string      `json:"doc,omitempty"`
+	Fields    []AvroField `json:"fields"`
+}
+
+// AvroField is a field of an Avro record, named after its column.
+type AvroField struct {
+	Name    string      `json:"name"`
+	Doc     string      `json:"doc,omitempty"`
+	Type    interface{} `json:"type"`
+	Default interface{} `json:"default,omitempty"`
+}
+
+// avroNull marks the null default of a nullable field, which omitempty would
+// otherwise drop.
+type avroNull struct{}
+
+func (avroNull) MarshalJSON() ([]byte, error) { return []byte("null"), nil }
+
+// writeAvroSchemas writes a <table>.avsc schema to dir for every table
+// matching one of the patterns, or every table when there are none.
+func writeAvroSchemas(dir string, patterns []string, namespace string, tables []Table, report *Report) {
+	if err := mkdirAll(dir); err != nil {
+		log.Fatalf("Failed to create Avro schema directory: %v", err)
+	}
+	for _, table := range tables {
+		if len(patterns) > 0 && !matchAny(patterns, table.DBTableName) {
+			continue
+		}
+		data, err := json.MarshalIndent(buildAvroSchema(table, namespace, report), "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to encode Avro schema of table %s: %v", table.DBTableName, err)
+		}
+		path := fmt.Sprintf("%s/%s.avsc", dir, table.DBTableName)
+		report.addTableFile(table.DBTableName, path, writeFile(path, append(data, '\n')))
+	}
+}
+
+// buildAvroSchema derives the Avro schema of the rows of a table. Fields are
+// named after the columns, as change data capture tools name them, and
+// nullable columns are unions with null defaulting to null.
+func buildAvroSchema(table Table, namespace string, report *Report) AvroSchema {
+	schema := AvroSchema{Type: "record", Name: table.TableName, Namespace: namespace}
+	columns := append([]Column{}, table.Columns...)
+	for _, money := range table.MoneyFields {
+		columns = append(columns, money.Columns...)
+	}
+	for _, column := range columns {
+		if column.Ignored {
+			continue
+		}
+		if !avroNamePattern.MatchString(column.GormName) {
+			report.tableWarnf(table.DBTableName, "%s.%s is not a valid Avro name and is left out of the Avro schema", table.DBTableName, column.GormName)
+			continue
+		}
+		field := AvroField{Name: column.GormName, Doc: column.Comment, Type: avroType(table.TableName, column)}
+		if column.Nullable {
+			field.Type = []interface{}{"null", field.Type}
+			field.Default = avroNull{}
+		}
+		schema.Fields = append(schema.Fields, field)
+	}
+	return schema
+}
+
+// avroType returns the Avro type of a column. Integer, decimal and time
+// columns are mapped from their database type, using the decimal, date,
+// time-millis/micros and timestamp-millis/micros logical types; other
+// columns from their Go type.
+func avroType(modelName string, column Column) interface{} {
+	databaseType := strings.ToLower(column.DatabaseType)
+	baseType := strings.TrimPrefix(column.Type, "*")
+
+	// Fractional seconds beyond milliseconds need the micros logical types
+	micros := false
+	if match := avroPrecisionPattern.FindStringSubmatch(databaseType); match != nil {
+		precision, _ := strconv.Atoi(match[1])
+		micros = precision > 3
+	} else if strings.Contains(databaseType, "time zone") || strings.HasPrefix(databaseType, "timestamptz") {
+		// Postgres defaults to microseconds
+		micros = true
+	}
+
+	name := strings.Fields(strings.SplitN(databaseType, "(", 2)[0])
+	switch {
+	case len(name) == 0:
+	case name[0] == "bigint" || name[0] == "int8" || name[0] == "bigserial":
+		return "long"
+	case name[0] == "int" || name[0] == "integer" || name[0] == "int4" || name[0] == "serial":
+		if strings.Contains(databaseType, "unsigned") {
+			return "long"
+		}
+		return "int"
+	case name[0] == "tinyint" && strings.HasPrefix(databaseType, "tinyint(1)") && baseType == "bool":
+		return "boolean"
+	case name[0] == "tinyint" || name[0] == "smallint" || name[0] == "mediumint" || name[0] == "int2" || name[0] == "smallserial":
+		return "int"
+	case name[0] == "decimal" || name[0] == "numeric":
+		if match := avroDecimalPattern.FindStringSubmatch(databaseType); match != nil {
+			precision, _ := strconv.Atoi(match[1])
+			scale, _ := strconv.Atoi(match[2])
+			return map[string]interface{}{"type": "bytes", "logicalType": "decimal", "precision": precision, "scale": scale}
+		}
+		// Unconstrained numerics have no fixed precision
+		return "string"
+	case name[0] == "date":
+		return map[string]string{"type": "int", "logicalType": "date"}
+	case name[0] == "time":
+		if micros {
+			return map[string]string{"type": "long", "logicalType": "time-micros"}
+		}
+		return map[string]string{"type": "int", "logicalType": "time-millis"}
+	case name[0] == "datetime" || strings.HasPrefix(name[0], "timestamp"):
+		if micros {
+			return map[string]string{"type": "long", "logicalType": "timestamp-micros"}
+		}
+		return map[string]string{"type": "long", "logicalType": "timestamp-millis"}
+	case name[0] == "uuid":
+		return map[string]string{"type": "string", "logicalType": "uuid"}
+	case name[0] == "enum":
+		values := enumValues(column.DatabaseType)
+		valid := len(values) > 0
+		for _, value := range values {
+			valid = valid && avroNamePattern.MatchString(value)
+		}
+		if valid {
+			return map[string]interface{}{"type": "enum", "name": modelName + column.Name, "symbols": values}
+		}
+		return "string"
+	}
+
+	switch baseType {
+	case "int64", "int", "uint32", "uint64", "uint", "sql.NullInt64", "null.Int":
+		return "long"
+	case "int32", "int16", "int8", "uint16", "uint8", "sql.NullInt32", "sql.NullInt16":
+		return "int"
+	case "float32":
+		return "float"
+	case "float64", "sql.NullFloat64", "null.Float":
+		return "double"
+	case "bool", "sql.NullBool", "null.Bool":
+		return "boolean"
+	case "[]byte", "[]uint8":
+		return "bytes"
+	case "uuid.UUID":
+		return map[string]string{"type": "string", "logicalType": "uuid"}
+	}
+	return "string"
+}
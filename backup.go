@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// backupDir is the directory writeFile copies files to before overwriting
+// them, set from -backup-dir. Backups are kept per run in a subdirectory
+// named after its start, so later runs never overwrite earlier backups.
+var backupDir string
+
+var backupRun = time.Now().UTC().Format("20060102T150405Z")
+
+// backupFile copies the content of path, which is about to be overwritten,
+// to the backup directory, under the same path relative to the working
+// directory.
+func backupFile(path string, content []byte, mode os.FileMode) error {
+	target := filepath.Join(backupDir, backupRun, filepath.FromSlash(backupPath(path)))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	return replaceFile(target, content, mode)
+}
+
+// backupPath returns the slash-separated path of the backup of path within
+// the backup of a run: its path relative to the working directory, with the
+// .. segments of files outside of it escaped as %2E%2E. % is escaped as %25,
+// so every file gets its own backup. Files on another volume keep their
+// absolute path, with the : of the volume escaped as %3A.
+func backupPath(path string) string {
+	relative := filepath.Clean(path)
+	if absolute, err := filepath.Abs(path); err == nil {
+		relative = absolute
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, absolute); err == nil {
+				relative = rel
+			}
+		}
+	}
+	segments := strings.Split(strings.TrimPrefix(filepath.ToSlash(relative), "/"), "/")
+	for i, segment := range segments {
+		segment = strings.ReplaceAll(segment, "%", "%25")
+		segment = strings.ReplaceAll(segment, ":", "%3A")
+		if segment == ".." {
+			segment = "%2E%2E"
+		}
+		segments[i] = segment
+	}
+	return strings.Join(segments, "/")
+}
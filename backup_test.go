@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupPath(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"models/User.go", "models/User.go"},
+		{"./models/../models/User.go", "models/User.go"},
+		{filepath.Join(wd, "models", "User.go"), "models/User.go"},
+		{"models/100%.go", "models/100%25.go"},
+		{"models/a:b.go", "models/a%3Ab.go"},
+		{"../models/User.go", "%2E%2E/models/User.go"},
+		{"models/%2E%2E/User.go", "models/%252E%252E/User.go"},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			if got := backupPath(test.path); got != test.want {
+				t.Errorf("backupPath(%q) = %q, want %q", test.path, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+var builderTemplate = `package models
+{{- if .Imports }}
+
+import (
+{{- range $i, $group := .Imports }}
+{{- if $i }}
+{{ end }}
+{{- range $group }}
+    {{with index $.ImportAliases .}}{{.}} {{end}}"{{.}}"
+{{- end }}
+{{- end }}
+)
+{{- end }}
+
+// {{.TableName}}Builder builds a {{.TableName}} field by field, e.g. for test data.
+{{- if .Required }}
+// Build fails unless the required fields, those of the NOT NULL columns
+// without a default, are set.
+{{- end }}
+type {{.TableName}}Builder struct {
+    model {{.TableName}}
+{{- if .Required }}
+    set   map[string]bool
+{{- end }}
+}
+
+// New{{.TableName}}Builder starts building a {{.TableName}}.
+func New{{.TableName}}Builder() *{{.TableName}}Builder {
+{{- if .Required }}
+    return &{{.TableName}}Builder{set: map[string]bool{}}
+{{- else }}
+    return &{{.TableName}}Builder{}
+{{- end }}
+}
+{{- range .Fields }}
+
+func (b *{{$.TableName}}Builder) {{.Name}}(value {{.Type}}) *{{$.TableName}}Builder {
+    b.model.{{.Name}} = value
+{{- if .Required }}
+    b.set["{{.Name}}"] = true
+{{- end }}
+    return b
+}
+{{- end }}
+
+// Build returns the {{.TableName}}{{if .Required}}, or an error naming the required fields that
+// were not set{{end}}.
+func (b *{{.TableName}}Builder) Build() ({{.TableName}}, error) {
+{{- if .Required }}
+    var missing []string
+    for _, field := range []string{ {{- range $i, $field := .Required}}{{if $i}}, {{end}}"{{$field}}"{{end -}} } {
+        if !b.set[field] {
+            missing = append(missing, field)
+        }
+    }
+    if len(missing) > 0 {
+        return {{.TableName}}{}, fmt.Errorf("{{.DBTableName}}: required fields not set: %s", strings.Join(missing, ", "))
+    }
+{{- end }}
+    return b.model, nil
+}
+`
+
+// Builder is the data of a generated <Model>Builder.go file.
+type Builder struct {
+	TableName     string
+	DBTableName   string
+	Imports       [][]string
+	ImportAliases map[string]string
+	Fields        []BuilderField
+	// Required are the fields Build requires to be set
+	Required []string
+}
+
+// BuilderField is a field of a model set by a method of its builder.
+type BuilderField struct {
+	AccessorField
+	Required bool
+}
+
+// requiredColumn reports whether a row cannot be inserted without a value
+// for the column: it is NOT NULL, without a default and not generated.
+func requiredColumn(column Column) bool {
+	return !column.Nullable && !column.HasDefault && !column.AutoIncrement && !column.Ignored
+}
+
+// requiredMoney reports whether a row cannot be inserted without a value for
+// either column of a money field.
+func requiredMoney(money MoneyField) bool {
+	for _, column := range money.Columns {
+		if requiredColumn(column) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildBuilder derives the builder of a model, with a method per field. ok is
+// false when a field is named Build, like the method finishing the builder.
+func buildBuilder(table Table) (Builder, bool) {
+	fields := accessorFields(table)
+	required := map[string]bool{}
+	for _, column := range table.Columns {
+		required[column.Name] = requiredColumn(column)
+	}
+	for _, money := range table.MoneyFields {
+		required[money.Name] = requiredMoney(money)
+	}
+
+	builder := Builder{
+		TableName:     table.TableName,
+		DBTableName:   table.DBTableName,
+		ImportAliases: table.ImportAliases,
+	}
+	for _, field := range fields {
+		if field.Name == "Build" {
+			return Builder{}, false
+		}
+		builder.Fields = append(builder.Fields, BuilderField{AccessorField: field, Required: required[field.Name]})
+		if required[field.Name] {
+			builder.Required = append(builder.Required, field.Name)
+		}
+	}
+	var extra []string
+	if len(builder.Required) > 0 {
+		extra = []string{"fmt", "strings"}
+	}
+	builder.Imports = fieldImports(table, fields, extra...)
+	return builder, true
+}
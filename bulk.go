@@ -0,0 +1,96 @@
+package main
+
+import "github.com/jinzhu/inflection"
+
+var bulkTemplate = `package models
+
+import (
+{{- if .ClickHouse }}
+    "errors"
+
+{{ end }}
+    "gorm.io/gorm"
+{{- if not .ClickHouse }}
+    "gorm.io/gorm/clause"
+{{- end }}
+)
+
+// BulkBatchSize is the number of rows the CreateMany functions insert per
+// statement.
+var BulkBatchSize = {{.BatchSize}}
+
+// OnConflict is how the CreateMany functions treat rows whose primary or
+// unique key already exists.
+type OnConflict int
+
+const (
+    // ConflictError fails the insert, the database's default
+    ConflictError OnConflict = iota
+    // ConflictSkip keeps the existing rows
+    ConflictSkip
+    // ConflictUpdate updates the existing rows with all columns of the new ones
+    ConflictUpdate
+)
+
+// createMany inserts rows in batches of BulkBatchSize, running the create
+// hooks of each batch.
+func createMany[T any](db *gorm.DB, rows []T, onConflict OnConflict) error {
+    if len(rows) == 0 {
+        return nil
+    }
+{{- if .ClickHouse }}
+    if onConflict != ConflictError {
+        return errors.New("models: ClickHouse has no conflict handling; use ConflictError")
+    }
+{{- else }}
+    switch onConflict {
+    case ConflictSkip:
+        db = db.Clauses(clause.OnConflict{DoNothing: true})
+    case ConflictUpdate:
+        db = db.Clauses(clause.OnConflict{UpdateAll: true})
+    }
+{{- end }}
+    return db.CreateInBatches(rows, BulkBatchSize).Error
+}
+{{- range .Models }}
+
+// CreateMany{{.Plural}} inserts {{.DBTableName}} rows in batches of BulkBatchSize.
+func CreateMany{{.Plural}}(db *gorm.DB, rows []{{.TableName}}, onConflict OnConflict) error {
+    return createMany(db, rows, onConflict)
+}
+{{- end }}
+`
+
+// Bulk is the data of the generated bulk.go file.
+type Bulk struct {
+	BatchSize  int
+	ClickHouse bool
+	Models     []BulkModel
+}
+
+// BulkModel is a model with a CreateMany function.
+type BulkModel struct {
+	TableName   string
+	DBTableName string
+	Plural      string
+}
+
+// buildBulk derives the bulk insert helpers of the models, except read-only
+// ones. batchSize defaults to 1000 rows.
+func buildBulk(driver string, tables []Table, batchSize int) Bulk {
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	bulk := Bulk{BatchSize: batchSize, ClickHouse: driver == "clickhouse"}
+	for _, table := range tables {
+		if table.ReadOnly {
+			continue
+		}
+		bulk.Models = append(bulk.Models, BulkModel{
+			TableName:   table.TableName,
+			DBTableName: table.DBTableName,
+			Plural:      inflection.Plural(table.TableName),
+		})
+	}
+	return bulk
+}
@@ -0,0 +1,228 @@
+package main
+
+import "fmt"
+
+var cacheTemplate = `package models
+
+import (
+    "context"
+    "time"
+)
+
+// Cache is the backend of the cached repositories, e.g. an adapter for Redis
+// or an in-process LRU cache. Get reports whether the key was found.
+type Cache interface {
+    Get(ctx context.Context, key string) ([]byte, bool, error)
+    Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+    Delete(ctx context.Context, key string) error
+}
+`
+
+var cachedRepositoryTemplate = `package models
+
+import (
+    "bytes"
+    "encoding/gob"
+    "fmt"
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// {{.TableName}}Repository {{if .ReadOnly}}reads{{else}}reads and writes{{end}} {{.DBTableName}} rows. It is
+// implemented by {{.TableName}}TenantRepository and {{.TableName}}CachedRepository.
+type {{.TableName}}Repository interface {
+    DB() *gorm.DB
+    Find(conds ...interface{}) ([]{{.TableName}}, error)
+    First(conds ...interface{}) (*{{.TableName}}, error)
+{{- if .Assign }}
+    Create(model *{{.TableName}}) error
+    Save(model *{{.TableName}}) error
+{{- end }}
+{{- if not .ReadOnly }}
+    Delete(model *{{.TableName}}) error
+{{- end }}
+{{- if .SoftDelete }}
+    Restore(model *{{.TableName}}) error
+{{- end }}
+}
+
+var (
+    _ {{.TableName}}Repository = (*{{.TableName}}TenantRepository)(nil)
+    _ {{.TableName}}Repository = (*{{.TableName}}CachedRepository)(nil)
+)
+
+// {{.TableName}}CachedRepository is a cache-aside decorator of a
+// {{.TableName}}TenantRepository. First by primary key reads through the
+{{- if .ReadOnly }}
+// cache.
+{{- else }}
+// cache, and the write methods invalidate the rows they write. Writes through
+// DB bypass the cache.
+{{- end }}
+type {{.TableName}}CachedRepository struct {
+    repository *{{.TableName}}TenantRepository
+    cache      Cache
+    ttl        time.Duration
+    // inTx is set in transactions, whose reads bypass the cache
+    inTx bool
+}
+
+func New{{.TableName}}CachedRepository(repository *{{.TableName}}TenantRepository, cache Cache, ttl time.Duration) *{{.TableName}}CachedRepository {
+    return &{{.TableName}}CachedRepository{repository: repository, cache: cache, ttl: ttl}
+}
+
+// WithTx returns the repository in the transaction tx. Its reads see the
+{{- if .ReadOnly }}
+// transaction's own writes and are not cached.
+{{- else }}
+// transaction's own writes and are not cached, while its writes still
+// invalidate the cache.
+{{- end }}
+func (r *{{.TableName}}CachedRepository) WithTx(tx *gorm.DB) *{{.TableName}}CachedRepository {
+    return &{{.TableName}}CachedRepository{repository: r.repository.WithTx(tx), cache: r.cache, ttl: r.ttl, inTx: true}
+}
+
+// key returns the cache key of a row, which includes the tenant so tenants
+// never read each other's rows.
+func (r *{{.TableName}}CachedRepository) key(id {{.KeyType}}) string {
+    return fmt.Sprintf("{{.DBTableName}}:%v:%v", r.repository.tenantID, id)
+}
+
+func (r *{{.TableName}}CachedRepository) DB() *gorm.DB {
+    return r.repository.DB()
+}
+
+func (r *{{.TableName}}CachedRepository) Find(conds ...interface{}) ([]{{.TableName}}, error) {
+    return r.repository.Find(conds...)
+}
+
+// First reads a row from the cache when conds is a single {{.KeyType}} primary
+// key, e.g. First(id), and from the repository otherwise or in transactions.
+// Cache errors fall back to the repository.
+func (r *{{.TableName}}CachedRepository) First(conds ...interface{}) (*{{.TableName}}, error) {
+    if len(conds) != 1 || r.inTx {
+        return r.repository.First(conds...)
+    }
+    id, ok := conds[0].({{.KeyType}})
+    if !ok {
+        return r.repository.First(conds...)
+    }
+    ctx := r.repository.db.Statement.Context
+    key := r.key(id)
+    if data, found, err := r.cache.Get(ctx, key); err == nil && found {
+        var model {{.TableName}}
+        if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&model); err == nil {
+            return &model, nil
+        }
+    }
+
+    model, err := r.repository.First(id)
+    if err != nil {
+        return nil, err
+    }
+    var buf bytes.Buffer
+    if err := gob.NewEncoder(&buf).Encode(model); err == nil {
+        // A failed Set only costs the next read a query
+        _ = r.cache.Set(ctx, key, buf.Bytes(), r.ttl)
+    }
+    return model, nil
+}
+{{- if .Assign }}
+
+func (r *{{.TableName}}CachedRepository) Create(model *{{.TableName}}) error {
+    if err := r.repository.Create(model); err != nil {
+        return err
+    }
+    return r.invalidate(model)
+}
+
+func (r *{{.TableName}}CachedRepository) Save(model *{{.TableName}}) error {
+    if err := r.repository.Save(model); err != nil {
+        return err
+    }
+    return r.invalidate(model)
+}
+{{- end }}
+{{- if not .ReadOnly }}
+
+func (r *{{.TableName}}CachedRepository) Delete(model *{{.TableName}}) error {
+    if err := r.repository.Delete(model); err != nil {
+        return err
+    }
+    return r.invalidate(model)
+}
+{{- if .SoftDelete }}
+
+func (r *{{.TableName}}CachedRepository) Restore(model *{{.TableName}}) error {
+    if err := r.repository.Restore(model); err != nil {
+        return err
+    }
+    return r.invalidate(model)
+}
+{{- end }}
+
+// invalidate removes the cached copy of a written row. Its error is
+// returned, as the cache may otherwise serve the old row until it expires.
+func (r *{{.TableName}}CachedRepository) invalidate(model *{{.TableName}}) error {
+    return r.cache.Delete(r.repository.db.Statement.Context, r.key(model.{{.Key}}))
+}
+{{- end }}
+`
+
+// CachedRepository is the data of a generated <Model>CachedRepository.go
+// file.
+type CachedRepository struct {
+	TableName   string
+	DBTableName string
+	// Key is the primary key field rows are cached by, of KeyType
+	Key     string
+	KeyType string
+	// Assign is set when the tenant repository has Create and Save methods
+	Assign bool
+	// SoftDelete is set when it has a Restore method
+	SoftDelete bool
+	// ReadOnly is set when it has no write methods
+	ReadOnly bool
+}
+
+// buildCachedRepository derives the cached repository of a tenant table. ok
+// is false when the table has no single-column primary key of a basic type
+// to key the cache by.
+func buildCachedRepository(table Table, repository TenantRepository) (CachedRepository, bool) {
+	var primaryKeys []Column
+	for _, column := range table.Columns {
+		if column.PrimaryKey {
+			primaryKeys = append(primaryKeys, column)
+		}
+	}
+	if len(primaryKeys) != 1 {
+		return CachedRepository{}, false
+	}
+	valueType, ok := queryValueType(primaryKeys[0].Type)
+	if !ok || valueType != primaryKeys[0].Type || valueType == "time.Time" {
+		return CachedRepository{}, false
+	}
+	return CachedRepository{
+		TableName:   table.TableName,
+		DBTableName: table.DBTableName,
+		Key:         primaryKeys[0].Name,
+		KeyType:     valueType,
+		Assign:      repository.Assign != "",
+		SoftDelete:  repository.SoftDelete != "",
+		ReadOnly:    repository.ReadOnly,
+	}, true
+}
+
+// writeCachedRepository writes the cached repository of a tenant table to
+// destPath and reports whether it did.
+func writeCachedRepository(table Table, repository TenantRepository, destPath string, report *Report) bool {
+	cached, ok := buildCachedRepository(table, repository)
+	if !ok {
+		report.tableWarnf(table.DBTableName, "%s has no single-column primary key of a basic type and gets no cached repository", table.DBTableName)
+		return false
+	}
+	path := fmt.Sprintf("%s/%sCachedRepository.go", destPath, table.TableName)
+	report.addTableFile(table.DBTableName, path, writeTemplate(cachedRepositoryTemplate, path, cached))
+	return true
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// CheckConstraint is a CHECK constraint of a table. Condition is the Go
+// expression used by the generated Validate method, and is empty when the
+// clause could not be translated.
+type CheckConstraint struct {
+	Name      string
+	Clause    string
+	Condition string
+	Message   string
+}
+
+const mysqlChecksQuery = `SELECT cc.CONSTRAINT_NAME, cc.CHECK_CLAUSE
+FROM information_schema.CHECK_CONSTRAINTS cc
+JOIN information_schema.TABLE_CONSTRAINTS tc ON tc.CONSTRAINT_SCHEMA = cc.CONSTRAINT_SCHEMA AND tc.CONSTRAINT_NAME = cc.CONSTRAINT_NAME
+WHERE tc.TABLE_SCHEMA = DATABASE() AND tc.TABLE_NAME = ? AND tc.CONSTRAINT_TYPE = 'CHECK'
+ORDER BY cc.CONSTRAINT_NAME`
+
+const postgresChecksQuery = `SELECT con.conname, pg_get_constraintdef(con.oid)
+FROM pg_constraint con
+JOIN pg_class rel ON rel.oid = con.conrelid
+JOIN pg_namespace nsp ON nsp.oid = rel.relnamespace
+WHERE con.contype = 'c' AND nsp.nspname = current_schema() AND rel.relname = ?
+ORDER BY con.conname`
+
+// loadCheckConstraints returns the CHECK constraints of a table. They are
+// available on MySQL 8.0.16+, TiDB, Postgres and CockroachDB.
+func loadCheckConstraints(db *gorm.DB, driver, tableName string) ([]CheckConstraint, error) {
+	var query string
+	switch driver {
+	case "mysql", "tidb":
+		query = mysqlChecksQuery
+	case "postgres", "cockroach":
+		query = postgresChecksQuery
+	default:
+		return nil, nil
+	}
+
+	rows, err := db.Raw(query, tableName).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var checks []CheckConstraint
+	for rows.Next() {
+		var check CheckConstraint
+		if err := rows.Scan(&check.Name, &check.Clause); err != nil {
+			return nil, err
+		}
+		check.Clause = normalizeCheckClause(check.Clause)
+		check.Message = fmt.Sprintf("check constraint %s violated: %s", check.Name, check.Clause)
+		checks = append(checks, check)
+	}
+	return checks, rows.Err()
+}
+
+var (
+	checkCastPattern       = regexp.MustCompile(`::[a-z ]+(\[\])?`)
+	checkIntroducerPattern = regexp.MustCompile(`_[a-z0-9]+'`)
+	checkLiteralPattern    = regexp.MustCompile(`\((-?\d+(?:\.\d+)?)\)`)
+	checkIdentPattern      = regexp.MustCompile(`(^|[^\w])\((\w+)\)( *[=<>!])`)
+	checkComparePattern    = regexp.MustCompile(`^(\w+) *(>=|<=|<>|!=|=|>|<) *(-?\d+(?:\.\d+)?)$`)
+	checkBetweenPattern    = regexp.MustCompile(`(?i)^(\w+) +between +(-?\d+(?:\.\d+)?) +and +(-?\d+(?:\.\d+)?)$`)
+	checkLengthPattern     = regexp.MustCompile(`(?i)^(?:char_length|length)\(+(\w+)\)+ *(>=|<=|<>|!=|=|>|<) *(\d+)$`)
+	checkInPattern         = regexp.MustCompile(`(?i)^(\w+) +in *\((.+)\)$`)
+	checkAnyPattern        = regexp.MustCompile(`(?i)^(\w+) *= *any *\(+array\[(.+)\]\)+$`)
+)
+
+// normalizeCheckClause strips the decoration databases add when reporting a
+// CHECK clause: the CHECK keyword, redundant parentheses, quoted identifiers,
+// casts and charset introducers.
+func normalizeCheckClause(clause string) string {
+	clause = strings.TrimSpace(clause)
+	clause = strings.TrimPrefix(clause, "CHECK ")
+	clause = strings.NewReplacer("`", "", `"`, "").Replace(clause)
+	clause = checkCastPattern.ReplaceAllString(clause, "")
+	clause = checkIntroducerPattern.ReplaceAllString(clause, "'")
+	clause = checkLiteralPattern.ReplaceAllString(clause, "$1")
+	for {
+		trimmed := trimOuterParens(clause)
+		if trimmed == clause {
+			break
+		}
+		clause = trimmed
+	}
+	// Drop parentheses around single identifiers, e.g. (status) = ANY (...)
+	clause = checkIdentPattern.ReplaceAllString(clause, "$1$2$3")
+	return clause
+}
+
+// trimOuterParens removes one pair of parentheses wrapping the whole clause.
+func trimOuterParens(clause string) string {
+	clause = strings.TrimSpace(clause)
+	if !strings.HasPrefix(clause, "(") || !strings.HasSuffix(clause, ")") {
+		return clause
+	}
+	depth := 0
+	for i, r := range clause {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 && i != len(clause)-1 {
+				return clause
+			}
+		}
+	}
+	return strings.TrimSpace(clause[1 : len(clause)-1])
+}
+
+// checkCondition translates a normalized CHECK clause into a Go expression on
+// the receiver m. Only simple comparisons against numeric literals, BETWEEN,
+// length limits and IN lists are supported; anything else returns "".
+func checkCondition(clause string, columns []Column) string {
+	fields := map[string]Column{}
+	for _, column := range columns {
+		fields[column.GormName] = column
+	}
+
+	if match := checkComparePattern.FindStringSubmatch(clause); match != nil {
+		if column, ok := fields[match[1]]; ok && literalFits(column.Type, match[3]) {
+			return fmt.Sprintf("m.%s %s %s", column.Name, goOperator(match[2]), match[3])
+		}
+	}
+	if match := checkBetweenPattern.FindStringSubmatch(clause); match != nil {
+		if column, ok := fields[match[1]]; ok && literalFits(column.Type, match[2]) && literalFits(column.Type, match[3]) {
+			return fmt.Sprintf("m.%s >= %s && m.%s <= %s", column.Name, match[2], column.Name, match[3])
+		}
+	}
+	if match := checkLengthPattern.FindStringSubmatch(clause); match != nil {
+		if column, ok := fields[match[1]]; ok && column.Type == "string" {
+			return fmt.Sprintf("utf8.RuneCountInString(m.%s) %s %s", column.Name, goOperator(match[2]), match[3])
+		}
+	}
+	match := checkInPattern.FindStringSubmatch(clause)
+	if match == nil {
+		match = checkAnyPattern.FindStringSubmatch(clause)
+	}
+	if match != nil {
+		if column, ok := fields[match[1]]; ok && column.Type == "string" {
+			var conditions []string
+			for _, value := range strings.Split(match[2], ",") {
+				value = strings.TrimSpace(value)
+				if len(value) < 2 || value[0] != '\'' || value[len(value)-1] != '\'' {
+					return ""
+				}
+				conditions = append(conditions, fmt.Sprintf("m.%s == %q", column.Name, strings.ReplaceAll(value[1:len(value)-1], "''", "'")))
+			}
+			return strings.Join(conditions, " || ")
+		}
+	}
+	return ""
+}
+
+func goOperator(operator string) string {
+	switch operator {
+	case "=":
+		return "=="
+	case "<>":
+		return "!="
+	default:
+		return operator
+	}
+}
+
+// literalFits reports whether a numeric literal can be compared with a field
+// of the given Go type without a compile error.
+func literalFits(goType, literal string) bool {
+	switch {
+	case strings.HasPrefix(goType, "float"):
+		return true
+	case strings.HasPrefix(goType, "int"):
+		return !strings.Contains(literal, ".")
+	case strings.HasPrefix(goType, "uint"):
+		return !strings.Contains(literal, ".") && !strings.HasPrefix(literal, "-")
+	default:
+		return false
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// modelFileSuffixes are the suffixes of the files generated per model after
+// its name, e.g. the UserQuery.go query builder of User.go
+var modelFileSuffixes = []string{"", "Query", "Filter", "Pagination", "Change", "TenantRepository", "CachedRepository", "Constructor", "Builder", "Getter", "Shard"}
+
+// generatedPrefixes start the headers of generated files, including the
+// header written by earlier versions
+var generatedPrefixes = []string{
+	"// Code generated by mysql-generate-gorm-models",
+	"// Generated by mysql-generate-gorm-models ",
+}
+
+// isGenerated reports whether content starts with the header of generated
+// files.
+func isGenerated(content []byte) bool {
+	for _, prefix := range generatedPrefixes {
+		if bytes.HasPrefix(content, []byte(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanOrphans removes the generated files in destPath that this run did
+// not generate, as their tables no longer exist: the files of the previous
+// run recorded in its lock. Files of the current lock, and files edited
+// since they were generated, are never removed. The files of models
+// recognized by their header and a struct named after the file that no lock
+// records cannot be told apart from edited ones, and are only warned about.
+func cleanOrphans(destPath string, locked, lock Lock, report *Report) {
+	current := map[string]bool{}
+	for _, list := range [][]string{report.Written, report.Updated, report.Unchanged, report.Merged, report.Conflicts} {
+		for _, path := range list {
+			current[filepath.Clean(path)] = true
+		}
+	}
+	for path := range lock.files() {
+		current[lockedFilePath(destPath, path)] = true
+	}
+
+	lockedFiles := locked.files()
+	paths := make([]string, 0, len(lockedFiles))
+	for path := range lockedFiles {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		orphan := lockedFilePath(destPath, path)
+		if current[orphan] {
+			continue
+		}
+		content, err := os.ReadFile(orphan)
+		if err != nil {
+			continue
+		}
+		if hashString(content) != lockedFiles[path] {
+			report.warnf("%s was edited since it was generated and is not removed", orphan)
+			current[orphan] = true
+			continue
+		}
+		removeOrphan(orphan, content, report)
+		current[orphan] = true
+	}
+
+	paths, err := filepath.Glob(filepath.Join(destPath, "*.go"))
+	if err != nil {
+		log.Fatalf("Failed to list %s: %v", destPath, err)
+	}
+	for _, path := range paths {
+		model := strings.TrimSuffix(filepath.Base(path), ".go")
+		if current[filepath.Clean(path)] || strings.HasSuffix(model, "_test") {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read %s: %v", path, err)
+		}
+		if !isGenerated(content) || !bytes.Contains(content, []byte("\ntype "+model+" struct {")) {
+			continue
+		}
+		for _, suffix := range modelFileSuffixes {
+			orphan := filepath.Join(destPath, model+suffix+".go")
+			if current[filepath.Clean(orphan)] {
+				continue
+			}
+			content, err := os.ReadFile(orphan)
+			if err != nil || !isGenerated(content) {
+				continue
+			}
+			report.warnf("%s looks generated but is not in the lock file, so it may have been edited, and is not removed", orphan)
+			current[filepath.Clean(orphan)] = true
+		}
+	}
+}
+
+// removeOrphan removes a generated file, after backing it up to backupDir,
+// or prints its removal in dry runs.
+func removeOrphan(path string, content []byte, report *Report) {
+	report.addTableFile("", path, fileRemoved)
+	if dryRun {
+		printDiff(path, content, nil)
+		return
+	}
+	if backupDir != "" {
+		if err := backupFile(path, content, 0o644); err != nil {
+			log.Fatalf("Failed to back up file %s: %v", path, err)
+		}
+	}
+	if err := os.Remove(path); err != nil {
+		log.Fatalf("Failed to remove %s: %v", path, err)
+	}
+}
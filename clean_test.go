@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCleanOrphans(t *testing.T) {
+	model := func(name string) string {
+		return generatedHeader() + "package models\n\ntype " + name + " struct {\n    Id int\n}\n"
+	}
+	tests := []struct {
+		name string
+		// files are the files of the destination, and locked those the
+		// previous lock records as generated with the given content
+		files  map[string]string
+		locked map[string]string
+		// current are the files this run generated
+		current  []string
+		removed  []string
+		warnings []string
+	}{
+		{
+			name:    "unedited orphan",
+			files:   map[string]string{"Order.go": model("Order"), "User.go": model("User")},
+			locked:  map[string]string{"Order.go": model("Order"), "User.go": model("User")},
+			current: []string{"User.go"},
+			removed: []string{"Order.go"},
+		},
+		{
+			name:     "edited orphan",
+			files:    map[string]string{"Order.go": model("Order") + "\nfunc (Order) Total() int { return 0 }\n"},
+			locked:   map[string]string{"Order.go": model("Order")},
+			warnings: []string{"Order.go was edited since it was generated and is not removed"},
+		},
+		{
+			name:   "orphan already removed",
+			locked: map[string]string{"Order.go": model("Order")},
+		},
+		{
+			name:  "unlocked generated files",
+			files: map[string]string{"Order.go": model("Order"), "OrderQuery.go": generatedHeader() + "package models\n"},
+			warnings: []string{
+				"Order.go looks generated but is not in the lock file, so it may have been edited, and is not removed",
+				"OrderQuery.go looks generated but is not in the lock file, so it may have been edited, and is not removed",
+			},
+		},
+		{
+			name:  "hand-written files",
+			files: map[string]string{"Order.go": "package models\n\ntype Order struct{}\n", "order_test.go": model("order_test")},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			destPath := t.TempDir()
+			for name, content := range test.files {
+				if err := os.WriteFile(filepath.Join(destPath, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			locked := Lock{Files: map[string]string{}}
+			for name, content := range test.locked {
+				locked.Files[name] = hashString([]byte(content))
+			}
+			report := &Report{}
+			for _, name := range test.current {
+				report.addFile(filepath.Join(destPath, name), fileUnchanged)
+			}
+
+			cleanOrphans(destPath, locked, Lock{}, report)
+
+			var removed []string
+			for _, path := range report.Removed {
+				removed = append(removed, filepath.Base(path))
+			}
+			if !slices.Equal(removed, test.removed) {
+				t.Errorf("removed %q, want %q", removed, test.removed)
+			}
+			var warnings []string
+			for _, warning := range report.Warnings {
+				warnings = append(warnings, strings.TrimPrefix(warning, destPath+string(filepath.Separator)))
+			}
+			if !slices.Equal(warnings, test.warnings) {
+				t.Errorf("warnings %q, want %q", warnings, test.warnings)
+			}
+			for name := range test.files {
+				_, err := os.Stat(filepath.Join(destPath, name))
+				if gone := os.IsNotExist(err); gone != slices.Contains(test.removed, name) {
+					t.Errorf("%s removed = %v, want %v", name, gone, !gone)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"strings"
+)
+
+// clickhouseColumnType maps a ClickHouse column type to a Go type and the
+// import it requires, if any. Wrapper types are unwrapped recursively:
+// Nullable(T) becomes a pointer, Array(T) a slice and LowCardinality(T) the
+// underlying type. ok is false when an unknown type falls back to string.
+func clickhouseColumnType(databaseType string) (string, string, bool) {
+	if inner, ok := clickhouseTypeArgs(databaseType, "Nullable"); ok {
+		goType, importPath, ok := clickhouseColumnType(inner)
+		return "*" + goType, importPath, ok
+	}
+	if inner, ok := clickhouseTypeArgs(databaseType, "LowCardinality"); ok {
+		return clickhouseColumnType(inner)
+	}
+	if inner, ok := clickhouseTypeArgs(databaseType, "Array"); ok {
+		goType, importPath, ok := clickhouseColumnType(inner)
+		return "[]" + goType, importPath, ok
+	}
+	if inner, ok := clickhouseTypeArgs(databaseType, "Map"); ok {
+		key, value := clickhouseMapArgs(inner)
+		keyType, keyImport, keyOk := clickhouseColumnType(strings.TrimSpace(key))
+		valueType, valueImport, valueOk := clickhouseColumnType(strings.TrimSpace(value))
+		if valueImport == "" {
+			valueImport = keyImport
+		}
+		return "map[" + keyType + "]" + valueType, valueImport, keyOk && valueOk
+	}
+
+	// Strip parameters such as DateTime64(3, 'UTC') or FixedString(16)
+	baseType, _, _ := strings.Cut(databaseType, "(")
+	switch baseType {
+	case "Int8":
+		return "int8", "", true
+	case "Int16":
+		return "int16", "", true
+	case "Int32":
+		return "int32", "", true
+	case "Int64":
+		return "int64", "", true
+	case "UInt8":
+		return "uint8", "", true
+	case "UInt16":
+		return "uint16", "", true
+	case "UInt32":
+		return "uint32", "", true
+	case "UInt64":
+		return "uint64", "", true
+	case "Float32":
+		return "float32", "", true
+	case "Float64":
+		return "float64", "", true
+	case "Bool", "Boolean":
+		return "bool", "", true
+	case "Date", "Date32", "DateTime", "DateTime64":
+		return "time.Time", "time", true
+	case "Decimal", "Decimal32", "Decimal64", "Decimal128", "Decimal256":
+		return "string", "", true // or use a custom decimal type
+	case "String", "FixedString", "UUID", "Enum8", "Enum16", "IPv4", "IPv6":
+		return "string", "", true
+	case "JSON":
+		return "json.RawMessage", "encoding/json", true
+	default:
+		return "string", "", false // default to string for any other types
+	}
+}
+
+// clickhouseTypeArgs returns the arguments of a wrapper type such as
+// Nullable(String) when databaseType uses the given wrapper.
+func clickhouseTypeArgs(databaseType, wrapper string) (string, bool) {
+	if !strings.HasPrefix(databaseType, wrapper+"(") || !strings.HasSuffix(databaseType, ")") {
+		return "", false
+	}
+	return databaseType[len(wrapper)+1 : len(databaseType)-1], true
+}
+
+// clickhouseMapArgs splits the arguments of a Map type into its key and value
+// types at the first comma outside of parentheses and enum labels, as the
+// value type may have arguments of its own, e.g. Map(String, Decimal(10, 2)).
+func clickhouseMapArgs(inner string) (string, string) {
+	depth := 0
+	quoted := false
+	for i, c := range inner {
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',':
+			if depth == 0 {
+				return inner[:i], inner[i+1:]
+			}
+		}
+	}
+	return inner, ""
+}
@@ -0,0 +1,67 @@
+package main
+
+import "testing"
+
+func TestClickhouseColumnType(t *testing.T) {
+	tests := []struct {
+		databaseType string
+		goType       string
+		importPath   string
+		ok           bool
+	}{
+		{"Int32", "int32", "", true},
+		{"UInt64", "uint64", "", true},
+		{"Float64", "float64", "", true},
+		{"Bool", "bool", "", true},
+		{"String", "string", "", true},
+		{"FixedString(16)", "string", "", true},
+		{"UUID", "string", "", true},
+		{"Enum8('a' = 1, 'b' = 2)", "string", "", true},
+		{"Decimal(10, 2)", "string", "", true},
+		{"Decimal128(4)", "string", "", true},
+		{"Date", "time.Time", "time", true},
+		{"DateTime64(3, 'UTC')", "time.Time", "time", true},
+		{"JSON", "json.RawMessage", "encoding/json", true},
+		{"Nullable(String)", "*string", "", true},
+		{"Nullable(DateTime)", "*time.Time", "time", true},
+		{"LowCardinality(String)", "string", "", true},
+		{"LowCardinality(Nullable(String))", "*string", "", true},
+		{"Array(Int64)", "[]int64", "", true},
+		{"Array(Nullable(Float32))", "[]*float32", "", true},
+		{"Map(String, UInt64)", "map[string]uint64", "", true},
+		{"Map(String, Decimal(10, 2))", "map[string]string", "", true},
+		{"Map(LowCardinality(String), Array(DateTime))", "map[string][]time.Time", "time", true},
+		{"Map(Enum8('a,b' = 1), Int8)", "map[string]int8", "", true},
+		{"Tuple(String, Int32)", "string", "", false},
+		{"Point", "string", "", false},
+		{"Array(Tuple(String, Int32))", "[]string", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.databaseType, func(t *testing.T) {
+			goType, importPath, ok := clickhouseColumnType(test.databaseType)
+			if goType != test.goType || importPath != test.importPath || ok != test.ok {
+				t.Errorf("clickhouseColumnType(%q) = %q, %q, %v, want %q, %q, %v", test.databaseType, goType, importPath, ok, test.goType, test.importPath, test.ok)
+			}
+		})
+	}
+}
+
+func TestClickhouseMapArgs(t *testing.T) {
+	tests := []struct {
+		inner, key, value string
+	}{
+		{"String, UInt64", "String", " UInt64"},
+		{"String, Decimal(10, 2)", "String", " Decimal(10, 2)"},
+		{"Tuple(String, Int8), String", "Tuple(String, Int8)", " String"},
+		{"Enum8('a,b' = 1), Int8", "Enum8('a,b' = 1)", " Int8"},
+		{"String", "String", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.inner, func(t *testing.T) {
+			key, value := clickhouseMapArgs(test.inner)
+			if key != test.key || value != test.value {
+				t.Errorf("clickhouseMapArgs(%q) = %q, %q, want %q, %q", test.inner, key, value, test.key, test.value)
+			}
+		})
+	}
+}
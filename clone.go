@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CloneEqual holds the generated statements of a model's Clone method and the
+// conditions of its Equal method.
+type CloneEqual struct {
+	CloneStatements []string
+	EqualConditions []string
+	Imports         []string
+}
+
+// buildCloneEqual derives the Clone and Equal methods of a model. Clone deep
+// copies pointers, slices, maps and associations; Equal compares the values
+// of columns and money fields only, using time.Time.Equal for timestamps so
+// the location and monotonic clock reading are ignored.
+func buildCloneEqual(columns []Column, money []MoneyField, associations []Association) CloneEqual {
+	var cloneEqual CloneEqual
+	addImport := func(importPath string) {
+		if !strings.Contains(strings.Join(cloneEqual.Imports, ","), importPath) {
+			cloneEqual.Imports = append(cloneEqual.Imports, importPath)
+		}
+	}
+
+	for _, column := range columns {
+		if statement := cloneStatement(column.Name, column.Type); statement != "" {
+			cloneEqual.CloneStatements = append(cloneEqual.CloneStatements, statement)
+		}
+
+		condition, importPath := equalCondition(column.Name, column.Type)
+		cloneEqual.EqualConditions = append(cloneEqual.EqualConditions, condition)
+		if importPath != "" {
+			addImport(importPath)
+		}
+	}
+
+	// Money holds two strings, which the struct copy already copies
+	for _, field := range money {
+		cloneEqual.EqualConditions = append(cloneEqual.EqualConditions, fmt.Sprintf("m.%[1]s == other.%[1]s", field.Name))
+	}
+
+	for _, association := range associations {
+		field := "m." + association.Name
+		if strings.HasPrefix(association.Type, "[]") {
+			cloneEqual.CloneStatements = append(cloneEqual.CloneStatements, fmt.Sprintf(`if %[1]s != nil {
+        clone.%[2]s = make(%[3]s, len(%[1]s))
+        for i, item := range %[1]s {
+            clone.%[2]s[i] = item.Clone()
+        }
+    }`, field, association.Name, association.Type))
+		} else {
+			cloneEqual.CloneStatements = append(cloneEqual.CloneStatements, fmt.Sprintf(`if %[1]s != nil {
+        value := %[1]s.Clone()
+        clone.%[2]s = &value
+    }`, field, association.Name))
+		}
+	}
+	return cloneEqual
+}
+
+// cloneStatement returns the statement deep copying a field into clone, or ""
+// when copying the struct is enough.
+func cloneStatement(name, goType string) string {
+	field := "m." + name
+	switch {
+	case strings.HasPrefix(goType, "[]*"):
+		return fmt.Sprintf(`if %[1]s != nil {
+        clone.%[2]s = make(%[3]s, len(%[1]s))
+        for i, item := range %[1]s {
+            if item != nil {
+                value := *item
+                clone.%[2]s[i] = &value
+            }
+        }
+    }`, field, name, goType)
+	case strings.HasPrefix(goType, "[]") || goType == "json.RawMessage":
+		return fmt.Sprintf(`if %[1]s != nil {
+        clone.%[2]s = append(%[3]s(nil), %[1]s...)
+    }`, field, name, goType)
+	case strings.HasPrefix(goType, "map["):
+		return fmt.Sprintf(`if %[1]s != nil {
+        clone.%[2]s = make(%[3]s, len(%[1]s))
+        for key, value := range %[1]s {
+            clone.%[2]s[key] = value
+        }
+    }`, field, name, goType)
+	case strings.HasPrefix(goType, "*"):
+		return fmt.Sprintf(`if %[1]s != nil {
+        value := *%[1]s
+        clone.%[2]s = &value
+    }`, field, name)
+	default:
+		return ""
+	}
+}
+
+// equalCondition returns the condition comparing a field of m and other, and
+// the import it requires, if any.
+func equalCondition(name, goType string) (string, string) {
+	a, b := "m."+name, "other."+name
+	switch {
+	case goType == "time.Time" || strings.HasPrefix(goType, "null."):
+		return fmt.Sprintf("%s.Equal(%s)", a, b), ""
+	case goType == "*time.Time":
+		return fmt.Sprintf("(%[1]s == nil) == (%[2]s == nil) && (%[1]s == nil || %[1]s.Equal(*%[2]s))", a, b), ""
+	case goType == "sql.NullTime":
+		return fmt.Sprintf("%[1]s.Valid == %[2]s.Valid && (!%[1]s.Valid || %[1]s.Time.Equal(%[2]s.Time))", a, b), ""
+	case goType == "[]byte" || goType == "[]uint8" || goType == "json.RawMessage":
+		return fmt.Sprintf("bytes.Equal(%s, %s)", a, b), "bytes"
+	case strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[") || strings.HasPrefix(goType, "*") && strings.Contains(goType, "."):
+		return fmt.Sprintf("reflect.DeepEqual(%s, %s)", a, b), "reflect"
+	case strings.HasPrefix(goType, "*"):
+		return fmt.Sprintf("(%[1]s == nil) == (%[2]s == nil) && (%[1]s == nil || *%[1]s == *%[2]s)", a, b), ""
+	case strings.Contains(goType, ".") && !strings.HasPrefix(goType, "sql.Null"):
+		// Types from other packages may not be comparable
+		return fmt.Sprintf("reflect.DeepEqual(%s, %s)", a, b), "reflect"
+	default:
+		return fmt.Sprintf("%s == %s", a, b), ""
+	}
+}
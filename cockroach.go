@@ -0,0 +1,29 @@
+package main
+
+import (
+	"gorm.io/gorm"
+)
+
+// cockroachAutoIncrement reports whether a column is filled by unique_rowid(),
+// which is how CockroachDB implements SERIAL columns by default. The Postgres
+// driver only recognises nextval() sequences and identity columns.
+func cockroachAutoIncrement(columnType gorm.ColumnType) bool {
+	defaultValue, ok := columnType.DefaultValue()
+	return ok && defaultValue == "unique_rowid()"
+}
+
+// cockroachHiddenColumns returns the hidden columns of a table, such as the
+// implicit rowid primary key CockroachDB adds to tables declared without one.
+func cockroachHiddenColumns(db *gorm.DB, tableName string) (map[string]bool, error) {
+	var names []string
+	err := db.Raw("SELECT column_name FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = ? AND is_hidden = 'YES'", tableName).Scan(&names).Error
+	if err != nil {
+		return nil, err
+	}
+
+	hidden := make(map[string]bool, len(names))
+	for _, name := range names {
+		hidden[name] = true
+	}
+	return hidden, nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"gorm.io/gorm"
+)
+
+// mysqlColumnCollations returns a type option with the collation of every
+// character column of a table, e.g. type:varchar(191) COLLATE utf8mb4_unicode_ci,
+// so AutoMigrate creates the column with the same collation.
+func mysqlColumnCollations(db *gorm.DB, tableName string) (map[string]string, error) {
+	rows, err := db.Raw("SELECT COLUMN_NAME, COLUMN_TYPE, COLLATION_NAME FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLLATION_NAME IS NOT NULL", tableName).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	collations := map[string]string{}
+	for rows.Next() {
+		var name, columnType, collation string
+		if err := rows.Scan(&name, &columnType, &collation); err != nil {
+			return nil, err
+		}
+		collations[name] = "type:" + columnType + " COLLATE " + collation
+	}
+	return collations, rows.Err()
+}
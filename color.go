@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// colorOutput colors the summary and -diff. It is set when stdout is a
+// terminal, unless -no-color is given or NO_COLOR is set.
+var colorOutput bool
+
+// ANSI colors of the output in terminals
+const (
+	colorBold   = "\x1b[1m"
+	colorDim    = "\x1b[2m"
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorCyan   = "\x1b[36m"
+	colorReset  = "\x1b[0m"
+)
+
+// colorTerminal reports whether stdout is a terminal and NO_COLOR is not
+// set.
+func colorTerminal() bool {
+	return os.Getenv("NO_COLOR") == "" && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// paint returns text in color if colorOutput is set.
+func paint(color, text string) string {
+	if !colorOutput {
+		return text
+	}
+	return color + text + colorReset
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Config holds generation settings read from the file given with -config.
+type Config struct {
+	// Profiles hold the settings of environments such as dev or prod,
+	// selected with -profile, keyed by name
+	Profiles    map[string]Profile  `json:"profiles"`
+	Polymorphic []PolymorphicConfig `json:"polymorphic"`
+	Collation   bool                `json:"collation"`
+	Checks      bool                `json:"checks"`
+	Enums       bool                `json:"enums"`
+	NullStyle   string              `json:"nullStyle"`
+	// DefaultTags includes the defaults of columns in gorm tags, so
+	// AutoMigrate creates them and inserts leave them to the database
+	DefaultTags bool `json:"defaultTags"`
+	// TimeMode maps datetime and timestamp columns to time.Time in UTC or
+	// local time, or to string: utc, local or string
+	TimeMode string `json:"timeMode"`
+	// Imports maps a package name or alias to its import path, e.g.
+	// "pgtype": "github.com/jackc/pgx/v5/pgtype"
+	Imports map[string]string `json:"imports"`
+	// ColumnTypes overrides the Go type of a column, keyed by "table.column"
+	// or "column". Qualified types must have their package in Imports.
+	ColumnTypes map[string]string `json:"columnTypes"`
+	// Serializers sets the GORM serializer of a column, keyed like
+	// ColumnTypes: gob for Go values stored in binary columns, or unixtime
+	// for int64 Unix seconds stored in time columns
+	Serializers map[string]string `json:"serializers"`
+	// Types declares custom types by name, which ColumnTypes can give
+	// instead of a Go type, so a mapping shared by many columns is written
+	// once
+	Types map[string]CustomType `json:"types"`
+	// Sensitive lists columns that are never serialized to JSON, as
+	// "table.column" or "column" patterns, e.g. "password_hash" or "*_token"
+	Sensitive []string `json:"sensitive"`
+	// Encrypted lists columns stored encrypted at rest, as "table.column" or
+	// "column" patterns. They are generated as EncryptedString fields, which
+	// encrypt and decrypt through Crypter, a Go expression such as
+	// "crypto.Default" whose package is in Imports. Without a Crypter, the
+	// models package's EncryptedStringCrypter must be set at startup.
+	Encrypted []string `json:"encrypted"`
+	Crypter   string   `json:"crypter"`
+	// Seed selects the rows the seed command exports per table, keyed by
+	// table name
+	Seed map[string]SeedConfig `json:"seed"`
+	// AvroDir is the directory a <table>.avsc schema is written to for the
+	// tables matching AvroTables, or every table when it is empty
+	AvroDir       string   `json:"avroDir"`
+	AvroTables    []string `json:"avroTables"`
+	AvroNamespace string   `json:"avroNamespace"`
+	// JSONSchemaDir is the directory a <table>.schema.json document is
+	// written to per table
+	JSONSchemaDir string `json:"jsonSchemaDir"`
+	// TSOut is the path of the TypeScript file written with an interface per
+	// model
+	TSOut string `json:"tsOut"`
+	// Sample is the number of rows sampled to find text columns whose values
+	// all hold UUIDs, JSON or booleans; SampleApply maps them to uuid.UUID,
+	// json.RawMessage or bool instead of only reporting them
+	Sample      int  `json:"sample"`
+	SampleApply bool `json:"sampleApply"`
+	// SoftDelete maps nullable SoftDeleteColumn columns, deleted_at by
+	// default, to gorm.DeletedAt and generates WithDeleted, OnlyDeleted and
+	// repository Restore methods for their tables
+	SoftDelete       bool   `json:"softDelete"`
+	SoftDeleteColumn string `json:"softDeleteColumn"`
+	// Bulk generates CreateMany functions in bulk.go inserting BatchSize rows
+	// per statement, 1000 by default
+	Bulk      bool `json:"bulk"`
+	BatchSize int  `json:"batchSize"`
+	// Filters generates a <Model>Filter struct per model with an Apply scope
+	Filters bool `json:"filters"`
+	// Pagination generates offset and cursor pagination helpers per model.
+	// PaginationColumns sets the column pages are sorted by per table, e.g.
+	// "posts": "created_at desc"; other tables are sorted by primary key.
+	Pagination        bool              `json:"pagination"`
+	PaginationColumns map[string]string `json:"paginationColumns"`
+	// Debezium generates a <Model>Change struct per model decoding Debezium
+	// change events. DebeziumDecimalHandling is the connector's
+	// decimal.handling.mode: precise (the default), string or double.
+	Debezium                bool   `json:"debezium"`
+	DebeziumDecimalHandling string `json:"debeziumDecimalHandling"`
+	// Migrate generates AllModels and AutoMigrateAll in migrate.go
+	Migrate bool `json:"migrate"`
+	// Gormigrate generates Migrations in gormigrate.go, a gormigrate
+	// migration per model
+	Gormigrate bool `json:"gormigrate"`
+	// TypeComments comments each field with the type and nullability of its
+	// column, e.g. // varchar(255) NOT NULL
+	TypeComments bool `json:"typeComments"`
+	// FieldOrder is the order of the fields of the models: "ordinal" (the
+	// default), "alphabetical", "grouped" or "fieldalign"
+	FieldOrder string `json:"fieldOrder"`
+	// IgnoreColumns are "table.column" or "column" patterns of columns left
+	// out of the models, e.g. "old_*". With IgnoreMode "tag" they are kept as
+	// fields tagged gorm:"-" instead of being skipped.
+	IgnoreColumns []string `json:"ignoreColumns"`
+	IgnoreMode    string   `json:"ignoreMode"`
+	// TagRules add struct tags and gorm options to the columns they match
+	TagRules []TagRule `json:"tagRules"`
+	// XMLTags, YAMLTags and BSONTags enable xml, yaml and bson struct tags
+	// with the given naming strategy: snake, camel, pascal or kebab
+	XMLTags  string `json:"xmlTags"`
+	YAMLTags string `json:"yamlTags"`
+	BSONTags string `json:"bsonTags"`
+	// MapstructureTags enables mapstructure tags for decoding with
+	// viper/mapstructure, using the same naming strategies
+	MapstructureTags string `json:"mapstructureTags"`
+	// BindingTags lists request binding tags to add for gin and echo: form,
+	// query, uri or param
+	BindingTags   []string `json:"bindingTags"`
+	Swag          bool     `json:"swag"`
+	QueryBuilders bool     `json:"queryBuilders"`
+	Constructors  bool     `json:"constructors"`
+	Builders      bool     `json:"builders"`
+	Getters       bool     `json:"getters"`
+	Stringer      bool     `json:"stringer"`
+	CloneEqual    bool     `json:"cloneEqual"`
+	OmitTableName bool     `json:"omitTableName"`
+	Tests         bool     `json:"tests"`
+	Quiet         bool     `json:"quiet"`
+	// ConstructorStyle is the style of the constructors, "required" taking
+	// the required columns or "options" taking functional options
+	ConstructorStyle string `json:"constructorStyle"`
+	// Incremental skips regenerating the tables whose schema is unchanged
+	// since the run that wrote the lock file in the destination
+	Incremental bool `json:"incremental"`
+	// PreHooks are shell commands run in the working directory before the
+	// schema is read, e.g. to run pending migrations, and PostHooks are run
+	// in the destination after generation, e.g. "goimports -w ." or
+	// "go build ./...". The run fails when one fails.
+	PreHooks  []string `json:"preHooks"`
+	PostHooks []string `json:"postHooks"`
+	// BackupDir is the directory files are copied to before they are
+	// overwritten
+	BackupDir string `json:"backupDir"`
+	// CheckGrants also verifies that the database user has no privileges
+	// that modify data, besides connecting with a read-only session
+	CheckGrants bool `json:"checkGrants"`
+	// CreatedByColumns and UpdatedByColumns are "table.column" or "column"
+	// patterns of columns holding the user who created or last updated a
+	// row. With AuditUserHooks, BeforeCreate and BeforeUpdate hooks fill them
+	// in through CurrentUserID.
+	CreatedByColumns []string `json:"createdByColumns"`
+	UpdatedByColumns []string `json:"updatedByColumns"`
+	AuditUserHooks   bool     `json:"auditUserHooks"`
+	// Money lists amount and currency column pairs generated as a single
+	// Money field
+	Money []MoneyConfig `json:"money"`
+	// VersionColumns are "table.column" or "column" patterns of optimistic
+	// locking version columns, e.g. "version" or "lock_version"
+	VersionColumns []string `json:"versionColumns"`
+	// TenantColumn is the column, e.g. tenant_id, that tables shared by
+	// tenants have. ScopeForTenant is generated when a table has it, and
+	// TenantRepositories adds repositories always applying it.
+	TenantColumn       string `json:"tenantColumn"`
+	TenantRepositories bool   `json:"tenantRepositories"`
+	// CacheRepositories adds a cache-aside decorator to each tenant
+	// repository, reading rows by primary key through a Cache
+	CacheRepositories bool `json:"cacheRepositories"`
+	// SkipAuditTables skips <table>_audit and <table>_history companions of
+	// generated tables instead of linking them to their base model
+	SkipAuditTables bool `json:"skipAuditTables"`
+	// IncludeSystemTables generates models for the tables of migration tools
+	// and extensions with -all, such as schema_migrations, which are skipped
+	// otherwise
+	IncludeSystemTables bool `json:"includeSystemTables"`
+	// ReadOnlyTables are the tables or path.Match patterns, e.g. "report_*",
+	// whose models are read-only like those of views, e.g. denormalized
+	// reporting tables
+	ReadOnlyTables []string `json:"readOnlyTables"`
+	// ShardPatterns are path.Match patterns of sharded table families, e.g.
+	// "events_*" for events_2023_01 and events_2023_02, which share a single
+	// model named after the table the pattern starts with
+	ShardPatterns []string `json:"shardPatterns"`
+	// ShardHelpers adds TableNameFor and InShard methods to the models of
+	// sharded families, choosing a shard by its key
+	ShardHelpers bool `json:"shardHelpers"`
+	// Templates assigns a model template file to tables, keyed by table name
+	// or path.Match pattern, e.g. "*_audit": "templates/audit.tmpl". Other
+	// tables use the default template.
+	Templates map[string]string `json:"templates"`
+	// IncludeColumns limits the columns of wide tables to the listed ones,
+	// keyed by table name. Primary keys are always included.
+	IncludeColumns map[string][]string `json:"includeColumns"`
+	// StringColumns lists the columns printed by String() per table, after
+	// the primary key
+	StringColumns map[string][]string `json:"stringColumns"`
+}
+
+// Profile holds the connection settings, tables and destination of an
+// environment. Flags given on the command line override it, and it overrides
+// the environment.
+type Profile struct {
+	Driver   string   `json:"driver"`
+	User     string   `json:"user"`
+	Password string   `json:"password"`
+	Host     string   `json:"host"`
+	Port     string   `json:"port"`
+	Name     string   `json:"name"`
+	Tables   []string `json:"tables"`
+	All      bool     `json:"all"`
+	Dest     string   `json:"dest"`
+	Package  string   `json:"package"`
+}
+
+// PolymorphicConfig declares a polymorphic association, e.g. comments whose
+// commentable_type/commentable_id columns point at posts or videos.
+type PolymorphicConfig struct {
+	Table  string             `json:"table"`
+	Name   string             `json:"name"`
+	HasOne bool               `json:"hasOne"`
+	Owners []PolymorphicOwner `json:"owners"`
+}
+
+// PolymorphicOwner is a table that owns a polymorphic association. Value is
+// stored in the type column and defaults to the owner's table name.
+type PolymorphicOwner struct {
+	Table string `json:"table"`
+	Value string `json:"value"`
+}
+
+// CustomType is a Go type declared in Types, e.g. "point": {"goType":
+// "geo.Point", "import": "github.com/acme/geo", "gormType": "point"}.
+type CustomType struct {
+	GoType string `json:"goType"`
+	// Import is the import path of the package of GoType, if it is qualified
+	Import string `json:"import"`
+	// GormType is the column type written to the gorm tag, which
+	// AutoMigrate creates the column with
+	GormType string `json:"gormType"`
+}
+
+func loadConfig(path string) (Config, error) {
+	var config Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	err = json.Unmarshal(data, &config)
+	return config, err
+}
+
+// columnTypeOverride returns the configured Go type of a column, preferring a
+// "table.column" entry over a bare "column" one.
+func columnTypeOverride(config Config, tableName, columnName string) (string, bool) {
+	if goType, ok := config.ColumnTypes[tableName+"."+columnName]; ok {
+		return goType, true
+	}
+	goType, ok := config.ColumnTypes[columnName]
+	return goType, ok
+}
+
+// registerCustomTypes checks the custom types of the config and adds their
+// imports to Imports, which qualified override types are resolved with.
+func registerCustomTypes(config *Config) error {
+	names := make([]string, 0, len(config.Types))
+	for name := range config.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		custom := config.Types[name]
+		qualifier := typeQualifier(custom.GoType)
+		switch {
+		case custom.GoType == "":
+			return fmt.Errorf("type %s has no goType", name)
+		case strings.ContainsAny(custom.GormType, ";\"`"):
+			return fmt.Errorf("gormType %s of type %s cannot be written in a gorm tag", custom.GormType, name)
+		case custom.Import == "":
+			continue
+		case qualifier == "":
+			return fmt.Errorf("type %s has an import, but goType %s is not qualified", name, custom.GoType)
+		}
+		if importPath, ok := config.Imports[qualifier]; ok && importPath != custom.Import {
+			return fmt.Errorf("type %s imports %s as %s, but imports maps %s to %s", name, custom.Import, qualifier, qualifier, importPath)
+		}
+		if config.Imports == nil {
+			config.Imports = map[string]string{}
+		}
+		config.Imports[qualifier] = custom.Import
+	}
+	return nil
+}
+
+// columnSerializer returns the serializer set for a column in Serializers,
+// if any.
+func columnSerializer(config Config, tableName, columnName string) string {
+	if serializer, ok := config.Serializers[tableName+"."+columnName]; ok {
+		return serializer
+	}
+	return config.Serializers[columnName]
+}
+
+// matchColumn reports whether a column matches one of the patterns, given as
+// "table.column" or "column" with path.Match wildcards.
+func matchColumn(patterns []string, tableName, columnName string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, tableName+"."+columnName); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, columnName); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// tableTemplate returns the model template of a table: the file configured
+// for its name, else for the first matching pattern in sorted order, else the
+// default template.
+func tableTemplate(config Config, tableName string) (string, error) {
+	file, ok := config.Templates[tableName]
+	if !ok {
+		patterns := make([]string, 0, len(config.Templates))
+		for pattern := range config.Templates {
+			patterns = append(patterns, pattern)
+		}
+		sort.Strings(patterns)
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, tableName); matched {
+				file, ok = config.Templates[pattern], true
+				break
+			}
+		}
+	}
+	if !ok {
+		return modelTemplate, nil
+	}
+
+	data, err := os.ReadFile(file)
+	return string(data), err
+}
@@ -0,0 +1,123 @@
+package main
+
+import "go/token"
+
+var constructorTemplate = `package models
+{{- if .Imports }}
+
+import (
+{{- range $i, $group := .Imports }}
+{{- if $i }}
+{{ end }}
+{{- range $group }}
+    {{with index $.ImportAliases .}}{{.}} {{end}}"{{.}}"
+{{- end }}
+{{- end }}
+)
+{{- end }}
+
+// New{{.TableName}} returns a {{.TableName}} with the values of the NOT NULL columns
+// without a default, which inserting it requires. The other fields are left
+// zero, so their columns get their default or NULL.
+func New{{.TableName}}({{range $i, $param := .Params}}{{if $i}}, {{end}}{{.Name}} {{.Type}}{{end}}) *{{.TableName}} {
+    return &{{.TableName}}{
+{{- range .Params }}
+        {{.Field}}: {{.Name}},
+{{- end }}
+    }
+}
+`
+
+var optionsConstructorTemplate = `package models
+{{- if .Imports }}
+
+import (
+{{- range $i, $group := .Imports }}
+{{- if $i }}
+{{ end }}
+{{- range $group }}
+    {{with index $.ImportAliases .}}{{.}} {{end}}"{{.}}"
+{{- end }}
+{{- end }}
+)
+{{- end }}
+
+// {{.TableName}}Option sets a field of the {{.TableName}} returned by New{{.TableName}}.
+type {{.TableName}}Option func(*{{.TableName}})
+
+// New{{.TableName}} returns a {{.TableName}} with the fields set by the With{{.TableName}}
+// options given. The other fields are left zero.
+func New{{.TableName}}(opts ...{{.TableName}}Option) *{{.TableName}} {
+    m := &{{.TableName}}{}
+    for _, opt := range opts {
+        opt(m)
+    }
+    return m
+}
+{{- range .Params }}
+
+func With{{$.TableName}}{{.Field}}(value {{.Type}}) {{$.TableName}}Option {
+    return func(m *{{$.TableName}}) {
+        m.{{.Field}} = value
+    }
+}
+{{- end }}
+`
+
+// Constructor is the data of a generated <Model>Constructor.go file.
+type Constructor struct {
+	TableName     string
+	Imports       [][]string
+	ImportAliases map[string]string
+	Params        []ConstructorParam
+}
+
+// ConstructorParam is a parameter of a constructor, setting the field of a
+// required column, or an option setting a field.
+type ConstructorParam struct {
+	Name  string
+	Field string
+	Type  string
+}
+
+// buildConstructor derives the constructor of a model in a style of
+// -constructor-style. The required style takes the fields of its required
+// columns in the order of the struct, followed by its required money fields,
+// which the struct declares after the columns. The options style has an
+// option per column and money field.
+func buildConstructor(table Table, style string) Constructor {
+	constructor := Constructor{TableName: table.TableName, ImportAliases: table.ImportAliases}
+	if style == "options" {
+		fields := accessorFields(Table{Columns: table.Columns, MoneyFields: table.MoneyFields})
+		for _, field := range fields {
+			constructor.Params = append(constructor.Params, ConstructorParam{Field: field.Name, Type: field.Type})
+		}
+		constructor.Imports = fieldImports(table, fields)
+		return constructor
+	}
+
+	var fields []AccessorField
+	for _, column := range table.Columns {
+		if !requiredColumn(column) {
+			continue
+		}
+		name := lowerFirst(column.Name)
+		if token.IsKeyword(name) {
+			name += "Value"
+		}
+		constructor.Params = append(constructor.Params, ConstructorParam{Name: name, Field: column.Name, Type: column.Type})
+		fields = append(fields, AccessorField{Name: column.Name, Type: column.Type})
+	}
+	for _, money := range table.MoneyFields {
+		if !requiredMoney(money) {
+			continue
+		}
+		name := lowerFirst(money.Name)
+		if token.IsKeyword(name) {
+			name += "Value"
+		}
+		constructor.Params = append(constructor.Params, ConstructorParam{Name: name, Field: money.Name, Type: "Money"})
+	}
+	constructor.Imports = fieldImports(table, fields)
+	return constructor
+}
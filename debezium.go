@@ -0,0 +1,290 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+var debeziumTemplate = `package models
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "math/big"
+    "reflect"
+    "sync"
+    "time"
+
+    "gorm.io/gorm/schema"
+)
+
+// Operations of Debezium change events.
+const (
+    ChangeCreate   = "c"
+    ChangeUpdate   = "u"
+    ChangeDelete   = "d"
+    ChangeRead     = "r" // snapshot
+    ChangeTruncate = "t"
+)
+
+// debeziumEvent is a change event payload with its rows keyed by column name.
+type debeziumEvent struct {
+    Before map[string]json.RawMessage ` + "`json:\"before\"`" + `
+    After  map[string]json.RawMessage ` + "`json:\"after\"`" + `
+    Op     string                     ` + "`json:\"op\"`" + `
+    TsMs   int64                      ` + "`json:\"ts_ms\"`" + `
+    Source json.RawMessage            ` + "`json:\"source\"`" + `
+}
+
+// decodeDebeziumEvent decodes a change event, unwrapping the payload when the
+// JSON converter wrote it with its schema.
+func decodeDebeziumEvent(data []byte, event *debeziumEvent) error {
+    var envelope struct {
+        Schema  json.RawMessage ` + "`json:\"schema\"`" + `
+        Payload json.RawMessage ` + "`json:\"payload\"`" + `
+    }
+    if err := json.Unmarshal(data, &envelope); err != nil {
+        return err
+    }
+    if envelope.Schema != nil && envelope.Payload != nil {
+        data = envelope.Payload
+    }
+    return json.Unmarshal(data, event)
+}
+
+var debeziumSchemas sync.Map
+
+// decodeDebeziumRow sets the fields of model from a before or after row.
+// columns maps column names to how Debezium encodes them; columns the model
+// has no field for are skipped.
+func decodeDebeziumRow(model interface{}, row map[string]json.RawMessage, columns map[string]string) error {
+    s, err := schema.Parse(model, &debeziumSchemas, schema.NamingStrategy{})
+    if err != nil {
+        return err
+    }
+    ctx := context.Background()
+    dst := reflect.ValueOf(model)
+    for name, raw := range row {
+        field := s.LookUpField(name)
+        if field == nil || field.DBName != name {
+            continue
+        }
+        value, err := debeziumValue(raw, columns[name])
+        if err != nil {
+            return fmt.Errorf("models: column %s: %w", name, err)
+        }
+        if field.Serializer != nil {
+            err = field.Serializer.Scan(ctx, field, dst, value)
+        } else {
+            err = field.Set(ctx, dst, value)
+        }
+        if err != nil {
+            return fmt.Errorf("models: column %s: %w", name, err)
+        }
+    }
+    return nil
+}
+
+// debeziumValue decodes a column value of the given encoding into a value the
+// field can be set from.
+func debeziumValue(raw json.RawMessage, encoding string) (interface{}, error) {
+    if string(raw) == "null" {
+        return nil, nil
+    }
+    var decimal int
+    if _, err := fmt.Sscanf(encoding, "decimal:%d", &decimal); err == nil {
+        // The unscaled value in big-endian two's complement
+        var data []byte
+        if err := json.Unmarshal(raw, &data); err != nil {
+            return nil, err
+        }
+        unscaled := new(big.Int).SetBytes(data)
+        if len(data) > 0 && data[0]&0x80 != 0 {
+            unscaled.Sub(unscaled, new(big.Int).Lsh(big.NewInt(1), uint(len(data)*8)))
+        }
+        scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimal)), nil)
+        return new(big.Rat).SetFrac(unscaled, scale).FloatString(decimal), nil
+    }
+
+    switch encoding {
+    case "bytes":
+        var data []byte
+        err := json.Unmarshal(raw, &data)
+        return data, err
+    case "json":
+        var text string
+        err := json.Unmarshal(raw, &text)
+        return []byte(text), err
+    case "date", "time-millis", "time-micros", "timestamp-millis", "timestamp-micros":
+        var text string
+        if json.Unmarshal(raw, &text) == nil {
+            // Zoned timestamps are ISO 8601 strings
+            for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+                if t, err := time.Parse(layout, text); err == nil {
+                    return t, nil
+                }
+            }
+            return nil, fmt.Errorf("cannot parse time %q", text)
+        }
+        var n int64
+        if err := json.Unmarshal(raw, &n); err != nil {
+            return nil, err
+        }
+        switch encoding {
+        case "date":
+            return time.Unix(n*24*60*60, 0).UTC(), nil
+        case "time-millis", "timestamp-millis":
+            return time.UnixMilli(n).UTC(), nil
+        default:
+            return time.UnixMicro(n).UTC(), nil
+        }
+    }
+
+    var value interface{}
+    decoder := json.NewDecoder(bytes.NewReader(raw))
+    decoder.UseNumber()
+    if err := decoder.Decode(&value); err != nil {
+        return nil, err
+    }
+    if n, ok := value.(json.Number); ok {
+        if i, err := n.Int64(); err == nil {
+            return i, nil
+        }
+        return n.Float64()
+    }
+    return value, nil
+}
+`
+
+var changeTemplate = `package models
+
+import "encoding/json"
+
+// {{.Model}}Change is a Debezium change event of the {{.Table}} table. Before is
+// nil for creates and snapshot reads, and After for deletes.
+type {{.Model}}Change struct {
+    Before *{{.Model}} ` + "`json:\"before\"`" + `
+    After  *{{.Model}} ` + "`json:\"after\"`" + `
+    // Op is ChangeCreate, ChangeUpdate, ChangeDelete, ChangeRead or ChangeTruncate
+    Op     string          ` + "`json:\"op\"`" + `
+    TsMs   int64           ` + "`json:\"ts_ms\"`" + `
+    Source json.RawMessage ` + "`json:\"source\"`" + `
+}
+
+// {{.Columns}} lists how Debezium encodes the columns of
+// {{.Table}} that are not plain JSON values.
+var {{.Columns}} = map[string]string{
+{{- range .Encodings }}
+    "{{.Column}}": "{{.Encoding}}",
+{{- end }}
+}
+
+// UnmarshalJSON decodes a change event written by Debezium's JSON converter,
+// with or without its schema. Rows are read by column name.
+func (c *{{.Model}}Change) UnmarshalJSON(data []byte) error {
+    var event debeziumEvent
+    if err := decodeDebeziumEvent(data, &event); err != nil {
+        return err
+    }
+    *c = {{.Model}}Change{Op: event.Op, TsMs: event.TsMs, Source: event.Source}
+    if event.Before != nil {
+        c.Before = &{{.Model}}{}
+        if err := decodeDebeziumRow(c.Before, event.Before, {{.Columns}}); err != nil {
+            return err
+        }
+    }
+    if event.After != nil {
+        c.After = &{{.Model}}{}
+        if err := decodeDebeziumRow(c.After, event.After, {{.Columns}}); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+`
+
+// Change is the data of a generated <Model>Change.go file.
+type Change struct {
+	Model     string
+	Table     string
+	Columns   string
+	Encodings []ChangeEncoding
+}
+
+// ChangeEncoding is how Debezium encodes the values of a column.
+type ChangeEncoding struct {
+	Column   string
+	Encoding string
+}
+
+// buildChange derives the change event of a table. decimalHandling is the
+// connector's decimal.handling.mode: only "precise" encodes decimals as
+// bytes, "string" and "double" as plain JSON values.
+func buildChange(table Table, decimalHandling string) Change {
+	change := Change{
+		Model:   table.TableName,
+		Table:   table.DBTableName,
+		Columns: lowerFirst(table.TableName) + "ChangeColumns",
+	}
+	columns := append([]Column{}, table.Columns...)
+	for _, money := range table.MoneyFields {
+		columns = append(columns, money.Columns...)
+	}
+	for _, column := range columns {
+		if encoding := debeziumEncoding(column, decimalHandling); encoding != "" && !column.Ignored {
+			change.Encodings = append(change.Encodings, ChangeEncoding{Column: column.GormName, Encoding: encoding})
+		}
+	}
+	sort.Slice(change.Encodings, func(i, j int) bool {
+		return change.Encodings[i].Column < change.Encodings[j].Column
+	})
+	return change
+}
+
+// debeziumEncoding returns how Debezium encodes the values of a column with
+// its default adaptive time precision: decimals as the bytes of their
+// unscaled value, dates as days and times as milli- or microseconds since the
+// epoch, binary columns as base64 and JSON columns as strings. It follows the
+// logical types of the column's Avro schema, and is empty for plain values.
+func debeziumEncoding(column Column, decimalHandling string) string {
+	if strings.TrimPrefix(column.Type, "*") == "json.RawMessage" {
+		return "json"
+	}
+	switch t := avroType("", column).(type) {
+	case string:
+		if t == "bytes" {
+			return "bytes"
+		}
+	case map[string]string:
+		if t["logicalType"] != "uuid" {
+			return t["logicalType"]
+		}
+	case map[string]interface{}:
+		if t["logicalType"] == "decimal" && decimalHandling == "precise" {
+			return fmt.Sprintf("decimal:%d", t["scale"])
+		}
+	}
+	return ""
+}
+
+// writeChanges writes a <Model>Change.go file per model and debezium.go,
+// which they share, to destPath.
+func writeChanges(destPath string, tables []Table, decimalHandling string, report *Report) {
+	switch decimalHandling {
+	case "":
+		decimalHandling = "precise"
+	case "precise", "string", "double":
+	default:
+		log.Fatalf("Invalid Debezium decimal handling %q: must be precise, string or double", decimalHandling)
+	}
+	path := fmt.Sprintf("%s/debezium.go", destPath)
+	report.addFile(path, writeTemplate(debeziumTemplate, path, nil))
+	for _, table := range tables {
+		path := fmt.Sprintf("%s/%sChange.go", destPath, table.TableName)
+		report.addTableFile(table.DBTableName, path, writeTemplate(changeTemplate, path, buildChange(table, decimalHandling)))
+	}
+}
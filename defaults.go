@@ -0,0 +1,97 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// currentTimeDefault matches the defaults setting a column to the time of
+// the insert, e.g. CURRENT_TIMESTAMP(3) on MySQL, now() on Postgres or
+// now64(3) on ClickHouse
+var currentTimeDefault = regexp.MustCompile(`(?i)^(current_timestamp|localtimestamp|now|now64)(\(\d*\))?$`)
+
+// keywordDefault matches the default expressions written without
+// parentheses, which GORM would quote like a string literal on other than
+// time fields
+var keywordDefault = regexp.MustCompile(`(?i)^(current_timestamp|current_date|current_time|localtime|localtimestamp|current_user|session_user)$`)
+
+// functionDefault matches default expressions calling a function, e.g.
+// uuid() or (now() + interval '1 day'), which GORM leaves unquoted
+var functionDefault = regexp.MustCompile(`^\(?[A-Za-z_][\w.]*\(.*\)\)?$`)
+
+// literalDefault matches the numbers and booleans GORM parses as the value
+// of the field
+var literalDefault = regexp.MustCompile(`(?i)^(-?\d+(\.\d+)?([eE][-+]?\d+)?|true|false)$`)
+
+// defaultOptions translates the default of a column into gorm tag options,
+// e.g. default:'draft' or default:uuid(). Defaults setting a time field to
+// the time of the insert also get autoCreateTime, or autoUpdateTime when
+// the column is also set on update, so the model holds the time after
+// saving. ok is false when the default cannot be written as a tag.
+func defaultOptions(value, goType string, onUpdate bool) ([]string, bool) {
+	value = strings.TrimSpace(value)
+	timeField := strings.TrimPrefix(goType, "*") == "time.Time"
+	switch {
+	case value == "" || strings.EqualFold(value, "null"):
+		return nil, true
+	// The tag parser splits options at ; and struct tags end at "
+	case strings.ContainsAny(value, "\";`\\\n") || !balancedDefault(value):
+		return nil, false
+	case timeField && currentTimeDefault.MatchString(value):
+		if onUpdate {
+			return []string{"default:" + value, "autoUpdateTime"}, true
+		}
+		return []string{"default:" + value, "autoCreateTime"}, true
+	case keywordDefault.MatchString(value) && !timeField:
+		return []string{"default:(" + value + ")"}, true
+	case keywordDefault.MatchString(value), functionDefault.MatchString(value), literalDefault.MatchString(value):
+		return []string{"default:" + value}, true
+	case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' && !strings.Contains(value[1:len(value)-1], "'"):
+		// Still quoted, e.g. by ClickHouse
+		return []string{"default:" + value}, true
+	case strings.Contains(value, "'"):
+		return nil, false
+	}
+	return []string{"default:'" + value + "'"}, true
+}
+
+// balancedDefault reports whether the parentheses of a default expression
+// are balanced outside of its string literals. The Postgres driver cuts
+// expressions at their first cast, e.g. (now() + '1 day'::interval).
+func balancedDefault(value string) bool {
+	depth := 0
+	quoted := false
+	for _, c := range value {
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0 && !quoted
+}
+
+// mysqlOnUpdateColumns returns the columns of a table set to the current
+// time on update, by ON UPDATE CURRENT_TIMESTAMP.
+func mysqlOnUpdateColumns(db *gorm.DB, tableName string) (map[string]bool, error) {
+	var names []string
+	err := db.Raw("SELECT COLUMN_NAME FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND LOWER(EXTRA) LIKE '%on update%'", tableName).Scan(&names).Error
+	if err != nil {
+		return nil, err
+	}
+
+	onUpdate := make(map[string]bool, len(names))
+	for _, name := range names {
+		onUpdate[name] = true
+	}
+	return onUpdate, nil
+}
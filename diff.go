@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dryRun, set by -dry-run and -diff, makes writeFile and the -clean removal
+// only report what they would do. diffOut is where -diff prints the changes
+// of every file to, or nil.
+var (
+	dryRun  bool
+	diffOut io.Writer
+)
+
+// diffContext is the number of unchanged lines around the changes of a hunk
+const diffContext = 3
+
+// mkdirAll creates dir and its parents, except in a dry run.
+func mkdirAll(dir string) error {
+	if dryRun {
+		return nil
+	}
+	return os.MkdirAll(dir, 0o755)
+}
+
+// DiffLine is a line of an edit script: kept (' '), removed ('-') or added
+// ('+').
+type DiffLine struct {
+	Kind byte
+	Text string
+}
+
+// printDiff writes the unified diff of path from its content before to
+// after to diffOut, if set. A nil before is a new file, and a nil after a
+// removed one.
+func printDiff(path string, before, after []byte) {
+	if diffOut == nil {
+		return
+	}
+	script := diffLines(splitLines(before), splitLines(after))
+	hunks := diffHunks(script)
+	if len(hunks) == 0 {
+		return
+	}
+	// Relative paths get the a/ and b/ prefixes of git diff, which patch -p1
+	// strips
+	name := filepath.ToSlash(filepath.Clean(path))
+	from, to := "a/"+name, "b/"+name
+	if filepath.IsAbs(path) {
+		from, to = name, name
+	}
+	if before == nil {
+		from = "/dev/null"
+	}
+	if after == nil {
+		to = "/dev/null"
+	}
+	fmt.Fprintln(diffOut, paint(colorBold, "--- "+from))
+	fmt.Fprintln(diffOut, paint(colorBold, "+++ "+to))
+	for _, hunk := range hunks {
+		fmt.Fprintln(diffOut, paint(colorCyan, hunk.header()))
+		for _, line := range script[hunk.start:hunk.end] {
+			switch line.Kind {
+			case '-':
+				fmt.Fprintln(diffOut, paint(colorRed, "-"+line.Text))
+			case '+':
+				fmt.Fprintln(diffOut, paint(colorGreen, "+"+line.Text))
+			default:
+				fmt.Fprintln(diffOut, " "+line.Text)
+			}
+		}
+	}
+}
+
+// splitLines splits content into lines without their line breaks.
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+// diffLines returns the shortest edit script turning a into b, found with
+// Myers' algorithm.
+func diffLines(a, b []string) []DiffLine {
+	n, m := len(a), len(b)
+	// v[offset+k] is the furthest x reached on diagonal k = x - y, and
+	// trace[d] the diagonals -d-1 to d+1 of v before step d
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		done := false
+		for k := -d; k <= d; k += 2 {
+			x := v[offset+k-1] + 1
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				done = true
+				break
+			}
+		}
+		if done {
+			break
+		}
+	}
+
+	// Walk back from the end through the diagonals of each step
+	var script []DiffLine
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		at := func(k int) int { return trace[d][k+d+1] }
+		k := x - y
+		previousK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			previousK = k + 1
+		}
+		previousX := at(previousK)
+		previousY := previousX - previousK
+		for x > previousX && y > previousY {
+			x--
+			y--
+			script = append(script, DiffLine{Kind: ' ', Text: a[x]})
+		}
+		if d > 0 {
+			if x == previousX {
+				y--
+				script = append(script, DiffLine{Kind: '+', Text: b[y]})
+			} else {
+				x--
+				script = append(script, DiffLine{Kind: '-', Text: a[x]})
+			}
+		}
+	}
+	for i, j := 0, len(script)-1; i < j; i, j = i+1, j-1 {
+		script[i], script[j] = script[j], script[i]
+	}
+	return script
+}
+
+// DiffHunk is a range of an edit script printed as a hunk, and the first
+// lines and line counts it covers of the old and new content.
+type DiffHunk struct {
+	start, end         int
+	oldStart, oldLines int
+	newStart, newLines int
+}
+
+func (h DiffHunk) header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.oldStart, h.oldLines), hunkRange(h.newStart, h.newLines))
+}
+
+// hunkRange formats the range of a hunk header. An empty range starts at
+// the line before it.
+func hunkRange(start, lines int) string {
+	if lines == 0 {
+		start--
+	}
+	if lines == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+// diffHunks groups the changes of an edit script into hunks with
+// diffContext lines of context, merging hunks whose context would overlap.
+func diffHunks(script []DiffLine) []DiffHunk {
+	var hunks []DiffHunk
+	for i := 0; i < len(script); i++ {
+		if script[i].Kind == ' ' {
+			continue
+		}
+		start := max(i-diffContext, 0)
+		end := i
+		for end < len(script) {
+			if script[end].Kind != ' ' {
+				end++
+				continue
+			}
+			kept := end
+			for kept < len(script) && script[kept].Kind == ' ' {
+				kept++
+			}
+			if kept == len(script) || kept-end > 2*diffContext {
+				end = min(end+diffContext, len(script))
+				break
+			}
+			end = kept
+		}
+		hunks = append(hunks, DiffHunk{start: start, end: end})
+		i = end - 1
+	}
+
+	// Number the lines of each hunk
+	for h := range hunks {
+		hunk := &hunks[h]
+		oldLines, newLines := countLines(script[:hunk.start])
+		hunk.oldStart, hunk.newStart = oldLines+1, newLines+1
+		hunk.oldLines, hunk.newLines = countLines(script[hunk.start:hunk.end])
+	}
+	return hunks
+}
+
+// countLines returns the number of old and new lines of part of an edit
+// script.
+func countLines(script []DiffLine) (int, int) {
+	oldLines, newLines := 0, 0
+	for _, line := range script {
+		if line.Kind != '+' {
+			oldLines++
+		}
+		if line.Kind != '-' {
+			newLines++
+		}
+	}
+	return oldLines, newLines
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// formatScript renders an edit script one line per line, prefixed by its kind.
+func formatScript(script []DiffLine) string {
+	var lines []string
+	for _, line := range script {
+		lines = append(lines, string(line.Kind)+line.Text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{name: "empty", want: ""},
+		{name: "equal", a: "a\nb\n", b: "a\nb\n", want: " a\n b"},
+		{name: "added", a: "a\nc\n", b: "a\nb\nc\n", want: " a\n+b\n c"},
+		{name: "removed", a: "a\nb\nc\n", b: "a\nc\n", want: " a\n-b\n c"},
+		{name: "replaced", a: "a\nb\nc\n", b: "a\nB\nc\n", want: " a\n-b\n+B\n c"},
+		{name: "new file", b: "a\nb\n", want: "+a\n+b"},
+		{name: "removed file", a: "a\nb\n", want: "-a\n-b"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			script := diffLines(splitLines([]byte(test.a)), splitLines([]byte(test.b)))
+			if got := formatScript(script); got != test.want {
+				t.Errorf("diffLines() =\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDiffHunks(t *testing.T) {
+	lines := func(from, to int) string {
+		var lines []string
+		for i := from; i <= to; i++ {
+			lines = append(lines, strings.Repeat("x", i))
+		}
+		return strings.Join(lines, "\n") + "\n"
+	}
+	tests := []struct {
+		name string
+		a, b string
+		want []string
+	}{
+		{name: "unchanged", a: lines(1, 3), b: lines(1, 3)},
+		{name: "one change", a: lines(1, 10), b: lines(1, 4) + "y\n" + lines(6, 10), want: []string{"@@ -2,7 +2,7 @@"}},
+		{name: "new file", b: lines(1, 2), want: []string{"@@ -0,0 +1,2 @@"}},
+		{name: "single line", a: "a\n", b: "b\n", want: []string{"@@ -1 +1 @@"}},
+		{name: "close changes", a: lines(1, 10), b: "y\n" + lines(2, 7) + "y\n" + lines(9, 10), want: []string{"@@ -1,10 +1,10 @@"}},
+		{name: "distant changes", a: lines(1, 20), b: "y\n" + lines(2, 19) + "y\n", want: []string{"@@ -1,4 +1,4 @@", "@@ -17,4 +17,4 @@"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var headers []string
+			for _, hunk := range diffHunks(diffLines(splitLines([]byte(test.a)), splitLines([]byte(test.b)))) {
+				headers = append(headers, hunk.header())
+			}
+			if strings.Join(headers, " ") != strings.Join(test.want, " ") {
+				t.Errorf("diffHunks() = %q, want %q", headers, test.want)
+			}
+		})
+	}
+}
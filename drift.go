@@ -0,0 +1,200 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ModelField is a field of an existing model, mapped to a column by its gorm
+// tag.
+type ModelField struct {
+	Name string
+	Type string
+	Gorm string
+}
+
+// drift compares the models in destPath with the models the schema of the
+// given tables would generate, and records every missing, extra or changed
+// field in report without writing any file, as well as the tables of the
+// lock file that were dropped. It takes the same config as generation,
+// which the models were generated with.
+func drift(db *gorm.DB, driver string, tableNames []string, destPath string, config Config, report *Report) {
+	models, tableModels, err := parseModels(destPath)
+	if err != nil {
+		log.Fatalf("Failed to parse models in %s: %v", destPath, err)
+	}
+	for _, tableName := range tableNames {
+		compareModel(buildModel(db, driver, tableName, nil, "", config, report), models, tableModels, report)
+	}
+
+	// The lock file also knows the tables generated before that were dropped
+	locked := loadLock(destPath)
+	lockedTables := make([]string, 0, len(locked.Tables))
+	for tableName := range locked.Tables {
+		lockedTables = append(lockedTables, tableName)
+	}
+	sort.Strings(lockedTables)
+	for _, tableName := range lockedTables {
+		if !db.Migrator().HasTable(tableName) {
+			report.driftf("%s was generated as %s but no longer exists", tableName, locked.Tables[tableName].Model.TableName)
+		}
+	}
+}
+
+// compareModel records the differences between the model of a table and
+// its parsed model, found by its TableName method or else by name.
+func compareModel(table Table, models map[string]map[string]ModelField, tableModels map[string]string, report *Report) {
+	model, ok := tableModels[table.DBTableName]
+	if !ok {
+		model = table.TableName
+	}
+	fields, ok := models[model]
+	if !ok {
+		report.driftf("%s has no model %s", table.DBTableName, model)
+		return
+	}
+
+	columns := map[string]bool{}
+	for _, column := range table.Columns {
+		columns[column.GormName] = true
+		if column.Ignored {
+			continue
+		}
+		field, ok := fields[column.GormName]
+		if !ok {
+			report.driftf("%s.%s has no field in %s", table.DBTableName, column.GormName, model)
+			continue
+		}
+		if field.Type != column.Type {
+			report.driftf("%s.%s is %s in %s.%s, the schema gives %s", table.DBTableName, column.GormName, field.Type, model, field.Name, column.Type)
+		}
+		if expected := gormTag(column); field.Gorm != expected {
+			report.driftf("%s.%s has gorm tag %q in %s.%s, the schema gives %q", table.DBTableName, column.GormName, field.Gorm, model, field.Name, expected)
+		}
+	}
+	for _, money := range table.MoneyFields {
+		for _, column := range money.Columns {
+			columns[column.GormName] = true
+		}
+	}
+	var extra []string
+	for columnName := range fields {
+		if !columns[columnName] {
+			extra = append(extra, columnName)
+		}
+	}
+	sort.Strings(extra)
+	for _, columnName := range extra {
+		report.driftf("%s.%s, the column of %s.%s, does not exist", table.DBTableName, columnName, model, fields[columnName].Name)
+	}
+}
+
+// gormTag returns the gorm tag the model template writes for a column.
+func gormTag(column Column) string {
+	return strings.Join(append([]string{"column:" + column.GormName}, column.GormOptions...), ";")
+}
+
+// parseModels parses the Go files in dir and returns the fields with a gorm
+// column of every struct, keyed by struct name and column, and the structs
+// whose TableName method returns a table, keyed by table.
+func parseModels(dir string) (map[string]map[string]ModelField, map[string]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, nil, err
+	}
+	models := map[string]map[string]ModelField{}
+	tableModels := map[string]string{}
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					typeSpec, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					structType, ok := typeSpec.Type.(*ast.StructType)
+					if !ok {
+						continue
+					}
+					models[typeSpec.Name.Name] = structColumns(structType)
+				}
+			case *ast.FuncDecl:
+				if model, table, ok := tableNameMethod(decl); ok {
+					tableModels[table] = model
+				}
+			}
+		}
+	}
+	return models, tableModels, nil
+}
+
+// structColumns returns the named fields of a struct whose gorm tag has a
+// column, keyed by the column.
+func structColumns(structType *ast.StructType) map[string]ModelField {
+	fields := map[string]ModelField{}
+	for _, field := range structType.Fields.List {
+		if field.Tag == nil || len(field.Names) != 1 {
+			continue
+		}
+		tag, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+		gormTag := reflect.StructTag(tag).Get("gorm")
+		for _, option := range strings.Split(gormTag, ";") {
+			if columnName, ok := strings.CutPrefix(option, "column:"); ok {
+				fields[columnName] = ModelField{Name: field.Names[0].Name, Type: types.ExprString(field.Type), Gorm: gormTag}
+				break
+			}
+		}
+	}
+	return fields
+}
+
+// tableNameMethod returns the receiver and table of a TableName method
+// returning a string literal, as generated models have.
+func tableNameMethod(decl *ast.FuncDecl) (string, string, bool) {
+	if decl.Name.Name != "TableName" || decl.Recv == nil || len(decl.Recv.List) != 1 || decl.Body == nil || len(decl.Body.List) != 1 {
+		return "", "", false
+	}
+	receiver := decl.Recv.List[0].Type
+	if star, ok := receiver.(*ast.StarExpr); ok {
+		receiver = star.X
+	}
+	ident, ok := receiver.(*ast.Ident)
+	if !ok {
+		return "", "", false
+	}
+	ret, ok := decl.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return "", "", false
+	}
+	literal, ok := ret.Results[0].(*ast.BasicLit)
+	if !ok || literal.Kind != token.STRING {
+		return "", "", false
+	}
+	table, err := strconv.Unquote(literal.Value)
+	if err != nil {
+		return "", "", false
+	}
+	return ident.Name, table, true
+}
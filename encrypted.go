@@ -0,0 +1,93 @@
+package main
+
+import "strings"
+
+var encryptedTemplate = `package models
+
+import (
+    "database/sql/driver"
+    "errors"
+    "fmt"
+{{- with .CrypterImport }}
+
+    {{with index $.ImportAliases .}}{{.}} {{end}}"{{.}}"
+{{- end }}
+)
+
+// Crypter encrypts and decrypts the values of encrypted columns.
+type Crypter interface {
+    Encrypt(plaintext []byte) ([]byte, error)
+    Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// EncryptedStringCrypter encrypts and decrypts every EncryptedString.
+{{- if .Crypter }}
+var EncryptedStringCrypter Crypter = {{.Crypter}}
+{{- else }}
+// It must be set before models with encrypted columns are read or written.
+var EncryptedStringCrypter Crypter
+{{- end }}
+
+// EncryptedString is a string stored encrypted at rest. It holds the
+// plaintext; values are encrypted when written and decrypted when read.
+type EncryptedString string
+
+// Scan decrypts a value read from the database.
+func (s *EncryptedString) Scan(value interface{}) error {
+    if EncryptedStringCrypter == nil {
+        return errors.New("models: EncryptedStringCrypter is not set")
+    }
+    var ciphertext []byte
+    switch v := value.(type) {
+    case []byte:
+        ciphertext = v
+    case string:
+        ciphertext = []byte(v)
+    default:
+        return fmt.Errorf("models: cannot scan %T into EncryptedString", value)
+    }
+    plaintext, err := EncryptedStringCrypter.Decrypt(ciphertext)
+    if err != nil {
+        return err
+    }
+    *s = EncryptedString(plaintext)
+    return nil
+}
+
+// Value encrypts the string for writing to the database.
+func (s EncryptedString) Value() (driver.Value, error) {
+    if EncryptedStringCrypter == nil {
+        return nil, errors.New("models: EncryptedStringCrypter is not set")
+    }
+    return EncryptedStringCrypter.Encrypt([]byte(s))
+}
+`
+
+// Encrypted is the data of the generated encrypted.go file.
+type Encrypted struct {
+	Crypter       string
+	CrypterImport string
+	ImportAliases map[string]string
+}
+
+// encryptedType returns the Go type of an encrypted column: EncryptedString,
+// or a pointer to it when the column is nullable.
+func encryptedType(nullable bool) string {
+	if nullable {
+		return "*EncryptedString"
+	}
+	return "EncryptedString"
+}
+
+// usesEncryptedString reports whether any generated model has an encrypted
+// column.
+func usesEncryptedString(tables []Table) bool {
+	for _, table := range tables {
+		for _, column := range table.Columns {
+			if strings.TrimPrefix(column.Type, "*") == "EncryptedString" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// EnumType is a string type generated for an enum column, with a constant
+// per value.
+type EnumType struct {
+	Name      string
+	Column    string
+	Constants []EnumConstant
+}
+
+// EnumConstant is a constant of an enum type.
+type EnumConstant struct {
+	Name  string
+	Value string
+}
+
+var enumIdentPattern = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+const postgresEnumsQuery = `SELECT t.typname, e.enumlabel
+FROM pg_type t
+JOIN pg_enum e ON e.enumtypid = t.oid
+JOIN pg_namespace n ON n.oid = t.typnamespace
+WHERE n.nspname = current_schema()
+ORDER BY t.typname, e.enumsortorder`
+
+// loadPostgresEnums returns the values of the enum types of the current
+// schema, keyed by type name.
+func loadPostgresEnums(db *gorm.DB) (map[string][]string, error) {
+	rows, err := db.Raw(postgresEnumsQuery).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	enums := map[string][]string{}
+	for rows.Next() {
+		var typeName, label string
+		if err := rows.Scan(&typeName, &label); err != nil {
+			return nil, err
+		}
+		enums[typeName] = append(enums[typeName], label)
+	}
+	return enums, rows.Err()
+}
+
+// buildEnumType names an enum type after its model and field, e.g.
+// OrderStatus, and its constants after their values, e.g. OrderStatusPending.
+// It returns false when two values would get the same constant name.
+func buildEnumType(modelName, fieldName, columnName string, values []string) (EnumType, bool) {
+	enum := EnumType{Name: modelName + fieldName, Column: columnName}
+	seen := map[string]bool{}
+	for _, value := range values {
+		suffix := camelCase(strings.ToLower(strings.Trim(enumIdentPattern.ReplaceAllString(value, "_"), "_")))
+		if suffix == "" {
+			suffix = "Empty"
+		}
+		name := enum.Name + suffix
+		if seen[name] {
+			return EnumType{}, false
+		}
+		seen[name] = true
+		enum.Constants = append(enum.Constants, EnumConstant{Name: name, Value: value})
+	}
+	return enum, len(enum.Constants) > 0
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// envFiles collects the .env files given by repeated -env flags.
+type envFiles []string
+
+func (files *envFiles) String() string {
+	return strings.Join(*files, ",")
+}
+
+func (files *envFiles) Set(value string) error {
+	*files = append(*files, value)
+	return nil
+}
+
+// loadEnvFiles sets the variables of the .env files that exist, with later
+// files overriding earlier ones, e.g. .env.local overriding .env. Variables
+// already set in the environment are kept, so they override every file.
+func loadEnvFiles(paths []string) error {
+	merged := map[string]string{}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		values, err := godotenv.Read(path)
+		if err != nil {
+			return err
+		}
+		for key, value := range values {
+			merged[key] = value
+		}
+	}
+	for key, value := range merged {
+		if _, ok := os.LookupEnv(key); ok {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
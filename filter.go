@@ -0,0 +1,86 @@
+package main
+
+var filterTemplate = `package models
+
+import (
+{{- range $i, $group := .Imports }}
+{{- if $i }}
+{{ end }}
+{{- range $group }}
+    "{{.}}"
+{{- end }}
+{{- end }}
+)
+
+// {{.TableName}}Filter selects {{.DBTableName}} rows by indexed columns and time
+// ranges. Nil fields do not filter, so a filter decoded from a request only
+// applies the parameters it was given.
+type {{.TableName}}Filter struct {
+{{- range .Fields }}
+    {{.Name}} *{{.Type}} ` + "`json:\"{{.JSONName}},omitempty\"`" + `
+{{- end }}
+}
+
+// Apply adds the conditions of the set fields to db. It is a scope, e.g.
+// db.Scopes(filter.Apply).Find(&rows).
+func (f {{.TableName}}Filter) Apply(db *gorm.DB) *gorm.DB {
+{{- range .Fields }}
+    if f.{{.Name}} != nil {
+        db = db.Where(clause.{{.Operator}}{Column: clause.Column{Table: clause.CurrentTable, Name: "{{.GormName}}"}, Value: *f.{{.Name}}})
+    }
+{{- end }}
+    return db
+}
+`
+
+// Filter is the data of a generated <Model>Filter.go file.
+type Filter struct {
+	TableName   string
+	DBTableName string
+	Imports     [][]string
+	Fields      []FilterField
+}
+
+// FilterField is a condition of a filter, e.g. CreatedAtFrom selecting rows
+// with created_at >= its value.
+type FilterField struct {
+	Name     string
+	GormName string
+	JSONName string
+	Type     string
+	// Operator is the clause type of the condition: Eq, Gte or Lt
+	Operator string
+}
+
+// buildFilter derives the filter of a table. Like the query builder's Where
+// methods, equality conditions cover primary keys and columns leading an
+// index, so every filter can use one; time columns get a half-open range of
+// <column>From and <column>To fields instead.
+func buildFilter(table Table) Filter {
+	filter := Filter{TableName: table.TableName, DBTableName: table.DBTableName}
+	leading := leadingIndexColumns(table)
+	imports := []string{}
+	for _, column := range table.Columns {
+		valueType, ok := queryValueType(column.Type)
+		if !ok || column.Ignored || column.Serializer != "" {
+			continue
+		}
+		if valueType == "time.Time" {
+			filter.Fields = append(filter.Fields,
+				FilterField{Name: column.Name + "From", GormName: column.GormName, JSONName: column.GormName + "_from", Type: valueType, Operator: "Gte"},
+				FilterField{Name: column.Name + "To", GormName: column.GormName, JSONName: column.GormName + "_to", Type: valueType, Operator: "Lt"},
+			)
+			if !containsString(imports, "time") {
+				imports = append(imports, "time")
+			}
+		} else if leading[column.GormName] || column.PrimaryKey {
+			filter.Fields = append(filter.Fields, FilterField{Name: column.Name, GormName: column.GormName, JSONName: column.GormName, Type: valueType, Operator: "Eq"})
+		}
+	}
+	imports = append(imports, "gorm.io/gorm")
+	if len(filter.Fields) > 0 {
+		imports = append(imports, "gorm.io/gorm/clause")
+	}
+	filter.Imports = Table{ModelImports: imports}.ImportGroups()
+	return filter
+}
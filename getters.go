@@ -0,0 +1,109 @@
+package main
+
+import "sort"
+
+var gettersTemplate = `package models
+{{- if .Imports }}
+
+import (
+{{- range $i, $group := .Imports }}
+{{- if $i }}
+{{ end }}
+{{- range $group }}
+    {{with index $.ImportAliases .}}{{.}} {{end}}"{{.}}"
+{{- end }}
+{{- end }}
+)
+{{- end }}
+
+// {{.TableName}}Getter reads the fields of the {{.TableName}} model. Code
+// depending on it instead of the model can be given a fake in tests.
+type {{.TableName}}Getter interface {
+{{- range .Fields }}
+    Get{{.Name}}() {{.Type}}
+{{- end }}
+}
+
+var _ {{.TableName}}Getter = {{.TableName}}{}
+{{- range .Fields }}
+
+func (m {{$.TableName}}) Get{{.Name}}() {{.Type}} {
+    return m.{{.Name}}
+}
+{{- end }}
+`
+
+// Getters is the data of a generated <Model>Getter.go file.
+type Getters struct {
+	TableName     string
+	Imports       [][]string
+	ImportAliases map[string]string
+	Fields        []AccessorField
+}
+
+// AccessorField is a field of a model read or set by generated methods: a
+// column, money field or association.
+type AccessorField struct {
+	Name string
+	Type string
+}
+
+// accessorFields returns the fields of a model, in the order of the struct.
+func accessorFields(table Table) []AccessorField {
+	var fields []AccessorField
+	for _, column := range table.Columns {
+		fields = append(fields, AccessorField{Name: column.Name, Type: column.Type})
+	}
+	for _, money := range table.MoneyFields {
+		fields = append(fields, AccessorField{Name: money.Name, Type: "Money"})
+	}
+	for _, association := range table.Associations {
+		fields = append(fields, AccessorField{Name: association.Name, Type: association.Type})
+	}
+	return fields
+}
+
+// fieldImports returns the imports of a model the types of fields need, and
+// the standard library packages of extra, grouped like ImportGroups.
+func fieldImports(table Table, fields []AccessorField, extra ...string) [][]string {
+	qualifiers := map[string]bool{}
+	for _, field := range fields {
+		if qualifier := typeQualifier(field.Type); qualifier != "" {
+			qualifiers[qualifier] = true
+		}
+	}
+	used := append([]string{}, extra...)
+	for _, importPath := range table.ModelImports {
+		name := packageName(importPath)
+		if alias, ok := table.ImportAliases[importPath]; ok {
+			name = alias
+		}
+		if qualifiers[name] && !containsString(used, importPath) {
+			used = append(used, importPath)
+		}
+	}
+	sort.Strings(used)
+	return Table{ModelImports: used}.ImportGroups()
+}
+
+// buildGetters derives the getters of a model, one per field. ok is false
+// when a field is named like the getter of another, e.g. GetEmail and Email,
+// which cannot both be declared.
+func buildGetters(table Table) (Getters, bool) {
+	fields := accessorFields(table)
+	names := map[string]bool{}
+	for _, field := range fields {
+		names[field.Name] = true
+	}
+	for _, field := range fields {
+		if names["Get"+field.Name] {
+			return Getters{}, false
+		}
+	}
+	return Getters{
+		TableName:     table.TableName,
+		Imports:       fieldImports(table, fields),
+		ImportAliases: table.ImportAliases,
+		Fields:        fields,
+	}, true
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrorHint is an entry of the catalog of errors the generator gives advice
+// on, matched against the message of the driver's error.
+type ErrorHint struct {
+	Pattern *regexp.Regexp
+	Hint    string
+}
+
+// errorHints is the catalog of errors with advice on fixing them. The first
+// matching entry is used.
+var errorHints = []ErrorHint{
+	{
+		Pattern: regexp.MustCompile(`(?i)connection refused|no such host|network is unreachable|i/o timeout`),
+		Hint:    "check -dbhost and -dbport, e.g. 3306 for MySQL, 4000 for TiDB, 5432 for Postgres, 26257 for CockroachDB or 9000 for ClickHouse, and that the server accepts TCP connections from this host",
+	},
+	{
+		Pattern: regexp.MustCompile(`(?i)x509:|tls:|insecure transport|no encryption|ssl`),
+		Hint:    "the server requires an encrypted connection, which the generator does not make; allow unencrypted connections from this host or connect through a tunnel, e.g. ssh -L",
+	},
+	{
+		Pattern: regexp.MustCompile(`(?i)access denied|password authentication failed|authentication failed`),
+		Hint:    "check -dbuser and -dbpassword, or where they are read from (see Settings Precedence in the README)",
+	},
+	{
+		Pattern: regexp.MustCompile(`(?i)unknown database|database ".*" does not exist|database \S+ doesn't exist`),
+		Hint:    "check -dbname",
+	},
+	{
+		Pattern: regexp.MustCompile(`context deadline exceeded`),
+		Hint:    "the server did not answer within -timeout; raise it, or check the load of the server",
+	},
+}
+
+// missingTable matches the errors of MySQL and TiDB, Postgres and
+// CockroachDB, and ClickHouse reading a table that does not exist
+var missingTable = regexp.MustCompile(`(?i)table \S+ doesn't exist|relation ".*" does not exist|UNKNOWN_TABLE`)
+
+// explain returns the message of err, followed by the hint of the catalog
+// entry it matches, if any.
+func explain(err error) string {
+	for _, entry := range errorHints {
+		if entry.Pattern.MatchString(err.Error()) {
+			return err.Error() + "\nhint: " + entry.Hint
+		}
+	}
+	return err.Error()
+}
+
+// explainTable explains an error reading a table. When the table does not
+// exist, the tables of the database with a similar name are suggested.
+func explainTable(db *gorm.DB, tableName string, err error) string {
+	if !missingTable.MatchString(err.Error()) {
+		return explain(err)
+	}
+	tableNames, listErr := db.Migrator().GetTables()
+	if listErr != nil {
+		return explain(err)
+	}
+	if matches := closeMatches(tableName, tableNames); len(matches) > 0 {
+		return fmt.Sprintf("%v\nhint: did you mean %s?", err, strings.Join(matches, ", "))
+	}
+	return fmt.Sprintf("%v\nhint: the database has no table named like %s, check -tables and -dbname", err, tableName)
+}
+
+// closeMatches returns up to three of the candidates closest to name,
+// ignoring case, i.e. within an edit distance of a third of its length or
+// containing it.
+func closeMatches(name string, candidates []string) []string {
+	name = strings.ToLower(name)
+	maxDistance := len(name) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+	distances := map[string]int{}
+	var matches []string
+	for _, candidate := range candidates {
+		lower := strings.ToLower(candidate)
+		distance := editDistance(name, lower)
+		if distance <= maxDistance || strings.Contains(lower, name) || strings.Contains(name, lower) {
+			distances[candidate] = distance
+			matches = append(matches, candidate)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return distances[matches[i]] < distances[matches[j]]
+	})
+	if len(matches) > 3 {
+		matches = matches[:3]
+	}
+	return matches
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
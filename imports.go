@@ -0,0 +1,56 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	versionSuffixPattern = regexp.MustCompile(`\.v\d+$`)
+	majorVersionPattern  = regexp.MustCompile(`^v\d+$`)
+)
+
+// packageName returns the default package name of an import path, e.g.
+// gopkg.in/guregu/null.v4 -> null and github.com/jackc/pgx/v5/pgtype -> pgtype.
+func packageName(importPath string) string {
+	parts := strings.Split(importPath, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && majorVersionPattern.MatchString(name) {
+		name = parts[len(parts)-2]
+	}
+	return versionSuffixPattern.ReplaceAllString(name, "")
+}
+
+// typeQualifier returns the package qualifier of a Go type, e.g.
+// *pgtype.Inet -> pgtype, or "" for builtin types.
+func typeQualifier(goType string) string {
+	goType = strings.TrimLeft(goType, "*[]")
+	qualifier, _, ok := strings.Cut(goType, ".")
+	if !ok {
+		return ""
+	}
+	return qualifier
+}
+
+// importAliases inverts the config's alias -> path imports into path -> alias.
+func importAliases(imports map[string]string) map[string]string {
+	aliases := make(map[string]string, len(imports))
+	for alias, importPath := range imports {
+		aliases[importPath] = alias
+	}
+	return aliases
+}
+
+// aliasType rewrites the qualifier of a Go type when its import is aliased,
+// e.g. null.String -> gnull.String for an import aliased as gnull.
+func aliasType(goType, importPath string, aliases map[string]string) string {
+	alias, ok := aliases[importPath]
+	if !ok || importPath == "" {
+		return goType
+	}
+	name := packageName(importPath)
+	if alias == name {
+		return goType
+	}
+	return regexp.MustCompile(`\b`+regexp.QuoteMeta(name)+`\.`).ReplaceAllString(goType, alias+".")
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// lockFileName is the file in the destination recording how its files were
+// generated: the generator version and options, the schema of each table
+// and the hash of each file
+const lockFileName = ".generate-gorm-models.lock"
+
+// legacyLockFileName is the lock file written by -incremental before every
+// run wrote one, read when there is no lock file yet
+const legacyLockFileName = ".gorm-models.lock"
+
+// Lock is the content of the lock file.
+type Lock struct {
+	// Key hashes everything besides the schema of a table that its files
+	// depend on, e.g. the generator version and config. Incremental
+	// generation only skips tables when it is unchanged.
+	Key string `json:"key"`
+	// Version, Driver, Package and Options are what the files were
+	// generated with, for audits
+	Version string                 `json:"version"`
+	Driver  string                 `json:"driver"`
+	Package string                 `json:"package"`
+	Options Config                 `json:"options"`
+	Tables  map[string]LockedTable `json:"tables"`
+	// Files are the hashes of the files generated for no table in
+	// particular, keyed by their path relative to the destination
+	Files map[string]string `json:"files"`
+}
+
+// LockedTable is a generated table: the hash of its schema, the model built
+// from it, which the shared files are generated from when the table is
+// skipped, and the hashes of its files.
+type LockedTable struct {
+	Schema string            `json:"schema"`
+	Model  Table             `json:"model"`
+	Files  map[string]string `json:"files"`
+}
+
+// loadLock reads the lock file in destPath. A missing or unreadable lock
+// file returns an empty lock, which regenerates every table.
+func loadLock(destPath string) Lock {
+	lock := Lock{Tables: map[string]LockedTable{}}
+	data, err := os.ReadFile(filepath.Join(destPath, lockFileName))
+	if os.IsNotExist(err) {
+		data, err = os.ReadFile(filepath.Join(destPath, legacyLockFileName))
+	}
+	if err != nil {
+		return lock
+	}
+	if err := json.Unmarshal(data, &lock); err != nil || lock.Tables == nil {
+		return Lock{Tables: map[string]LockedTable{}}
+	}
+	return lock
+}
+
+// recordFiles sets the hashes of the content generated for the files of the
+// report in the lock, which differ from the files when edits were merged
+// into them. The files of skipped tables keep their hashes.
+func (lock *Lock) recordFiles(destPath string, report *Report) {
+	lock.Files = map[string]string{}
+	for _, file := range report.Files {
+		if file.Action == fileSkipped || file.Action == fileRemoved {
+			continue
+		}
+		hash, ok := generatedHashes[filepath.Clean(file.Path)]
+		if !ok {
+			continue
+		}
+		path := lockPath(destPath, file.Path)
+		if entry, ok := lock.Tables[file.Table]; ok {
+			if entry.Files == nil {
+				entry.Files = map[string]string{}
+			}
+			entry.Files[path] = hash
+			lock.Tables[file.Table] = entry
+		} else {
+			lock.Files[path] = hash
+		}
+	}
+}
+
+// files returns the hashes of all files of the lock, keyed by path relative
+// to the destination.
+func (lock Lock) files() map[string]string {
+	files := map[string]string{}
+	for path, hash := range lock.Files {
+		files[path] = hash
+	}
+	for _, entry := range lock.Tables {
+		for path, hash := range entry.Files {
+			files[path] = hash
+		}
+	}
+	return files
+}
+
+// lockPath returns the path of a generated file relative to destPath, as the
+// lock file records it.
+func lockPath(destPath, path string) string {
+	if relative, err := filepath.Rel(destPath, path); err == nil {
+		return filepath.ToSlash(relative)
+	}
+	return filepath.ToSlash(path)
+}
+
+// lockedFilePath returns the path of a file recorded in the lock file of
+// destPath.
+func lockedFilePath(destPath, path string) string {
+	path = filepath.FromSlash(path)
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(destPath, path)
+}
+
+// writeLock writes the lock file to destPath, unless this is a dry run.
+func writeLock(destPath string, lock Lock, report *Report) {
+	if dryRun {
+		return
+	}
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode lock file: %v", err)
+	}
+	path := fmt.Sprintf("%s/%s", destPath, lockFileName)
+	report.addFile(path, writeFile(path, append(data, '\n')))
+}
+
+// lockKey hashes the inputs of generation other than the schema of each
+// table: the generator version, driver, package, config and model
+// templates, the generated tables and foreign keys between them, which add
+// associations, and on Postgres the enum types.
+func lockKey(db *gorm.DB, driver string, tableNames []string, foreignKeys []ForeignKey, config Config) string {
+	templates := map[string]string{}
+	for _, tableName := range tableNames {
+		text, err := tableTemplate(config, tableName)
+		if err != nil {
+			log.Fatalf("Failed to read template for table %s: %v", tableName, err)
+		}
+		templates[tableName] = text
+	}
+	var enums map[string][]string
+	if driver == "postgres" || driver == "cockroach" {
+		var err error
+		enums, err = loadPostgresEnums(db)
+		if err != nil {
+			log.Fatalf("Failed to get enum types: %v", err)
+		}
+	}
+	sortedTables := append([]string{}, tableNames...)
+	sort.Strings(sortedTables)
+
+	data, err := json.Marshal(map[string]interface{}{
+		"version":     versionString(),
+		"driver":      driver,
+		"package":     modelPackage,
+		"config":      lockOptions(config),
+		"templates":   templates,
+		"tables":      sortedTables,
+		"foreignKeys": foreignKeys,
+		"enums":       enums,
+	})
+	if err != nil {
+		log.Fatalf("Failed to encode lock key: %v", err)
+	}
+	return hashString(data)
+}
+
+// lockOptions returns the config without the settings that do not change
+// the files, such as hooks, and the profiles holding credentials.
+func lockOptions(config Config) Config {
+	config.Quiet = false
+	config.Incremental = false
+	config.BackupDir = ""
+	config.CheckGrants = false
+	config.PreHooks = nil
+	config.PostHooks = nil
+	config.Profiles = nil
+	return config
+}
+
+// schemaHash hashes the CREATE TABLE statement of a table, which covers its
+// columns, indexes, constraints and comments, or the CREATE VIEW statement
+// of a view. Views the database shows no statement for, such as system
+// views, are hashed by their columns.
+func schemaHash(db *gorm.DB, driver, tableName string) string {
+	statement, err := createTableStatement(db, driver, tableName)
+	if err != nil {
+		if view, viewErr := isView(db, driver, tableName); viewErr == nil && view {
+			return viewColumnsHash(db, tableName)
+		}
+		log.Fatalf("Failed to get the definition of table %s: %s", tableName, explainTable(db, tableName, err))
+	}
+	return hashString([]byte(statement))
+}
+
+// viewColumnsHash hashes the names, types and nullability of the columns of
+// a view.
+func viewColumnsHash(db *gorm.DB, tableName string) string {
+	columnTypes, err := db.Migrator().ColumnTypes(tableName)
+	if err != nil {
+		log.Fatalf("Failed to get columns of view %s: %s", tableName, explainTable(db, tableName, err))
+	}
+	var columns []string
+	for _, columnType := range columnTypes {
+		nullable, _ := columnType.Nullable()
+		columns = append(columns, fmt.Sprintf("%s %s %t", columnType.Name(), columnType.DatabaseTypeName(), nullable))
+	}
+	data, err := json.Marshal(columns)
+	if err != nil {
+		log.Fatalf("Failed to encode columns of view %s: %v", tableName, err)
+	}
+	return hashString(data)
+}
+
+func hashString(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// postgresUniqueIndexesQuery lists the key columns of the unique indexes of
+// a table in order, including those of unique constraints. Expression and
+// partial indexes are left out, since tags cannot recreate them.
+const postgresUniqueIndexesQuery = `SELECT ci.relname, a.attname
+FROM pg_index i
+JOIN pg_class ct ON ct.oid = i.indrelid
+JOIN pg_namespace n ON n.oid = ct.relnamespace
+JOIN pg_class ci ON ci.oid = i.indexrelid
+CROSS JOIN LATERAL unnest(i.indkey::int2[]) WITH ORDINALITY AS k(attnum, position)
+JOIN pg_attribute a ON a.attrelid = ct.oid AND a.attnum = k.attnum
+WHERE n.nspname = current_schema() AND ct.relname = ?
+	AND i.indisunique AND NOT i.indisprimary AND i.indexprs IS NULL AND i.indpred IS NULL
+	AND k.position <= i.indnkeyatts
+ORDER BY ci.relname, k.position`
+
+// postgresUniqueIndexes returns the unique indexes of a table other than its
+// primary key, with their columns in index order.
+func postgresUniqueIndexes(db *gorm.DB, tableName string) ([]Index, error) {
+	rows, err := db.Raw(postgresUniqueIndexesQuery, tableName).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexes []Index
+	for rows.Next() {
+		var indexName, columnName string
+		if err := rows.Scan(&indexName, &columnName); err != nil {
+			return nil, err
+		}
+		if len(indexes) == 0 || indexes[len(indexes)-1].Name != indexName {
+			indexes = append(indexes, Index{Name: indexName, Unique: true})
+		}
+		indexes[len(indexes)-1].Columns = append(indexes[len(indexes)-1].Columns, columnName)
+	}
+	return indexes, rows.Err()
+}
+
+// mysqlIndexDetails sets the class and prefix lengths of the indexes of a
+// table, which GORM does not read.
+func mysqlIndexDetails(db *gorm.DB, tableName string, indexes []Index) error {
+	rows, err := db.Raw("SELECT INDEX_NAME, INDEX_TYPE, SUB_PART FROM information_schema.STATISTICS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? ORDER BY INDEX_NAME, SEQ_IN_INDEX", tableName).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	classes := map[string]string{}
+	lengths := map[string][]int{}
+	for rows.Next() {
+		var indexName, indexType string
+		var subPart sql.NullInt64
+		if err := rows.Scan(&indexName, &indexType, &subPart); err != nil {
+			return err
+		}
+		if indexType == "FULLTEXT" || indexType == "SPATIAL" {
+			classes[indexName] = indexType
+		}
+		lengths[indexName] = append(lengths[indexName], int(subPart.Int64))
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range indexes {
+		indexes[i].Class = classes[indexes[i].Name]
+		if length := lengths[indexes[i].Name]; len(length) == len(indexes[i].Columns) {
+			indexes[i].Lengths = length
+		}
+	}
+	return nil
+}
+
+// compositeUniqueIndex reports whether an index is a unique index of several
+// columns, other than the primary key.
+func compositeUniqueIndex(index Index) bool {
+	return index.Unique && !index.PrimaryKey && len(index.Columns) > 1
+}
+
+// taggedIndex reports whether an index is written to the gorm tags of its
+// columns: composite unique indexes, which upserts refer to by name, and
+// FULLTEXT, SPATIAL and prefix indexes, which AutoMigrate cannot infer from
+// the fields.
+func taggedIndex(index Index) bool {
+	if index.PrimaryKey {
+		return false
+	}
+	prefix := false
+	for _, length := range index.Lengths {
+		prefix = prefix || length > 0
+	}
+	return compositeUniqueIndex(index) || index.Class != "" || prefix
+}
+
+// indexDefinition describes an index for comments, e.g.
+// ft_posts FULLTEXT (title, body) or idx_url (url(191)).
+func indexDefinition(index Index) string {
+	columns := make([]string, len(index.Columns))
+	for i, name := range index.Columns {
+		columns[i] = name
+		if i < len(index.Lengths) && index.Lengths[i] > 0 {
+			columns[i] = fmt.Sprintf("%s(%d)", name, index.Lengths[i])
+		}
+	}
+	parts := []string{index.Name}
+	if index.Unique {
+		parts = append(parts, "UNIQUE")
+	}
+	if index.Class != "" {
+		parts = append(parts, index.Class)
+	}
+	parts = append(parts, "("+strings.Join(columns, ", ")+")")
+	return strings.Join(parts, " ")
+}
+
+// indexTags adds an index to the gorm tags of its columns, as index:<name> or
+// uniqueIndex:<name> with the position of each column of composite indexes
+// as its priority, so AutoMigrate creates the same index. ok is false when a
+// column of the index is not in the model, which would narrow the index; no
+// tags are added then.
+func indexTags(columns []Column, index Index) bool {
+	positions := make([]int, len(index.Columns))
+	for i, name := range index.Columns {
+		positions[i] = -1
+		for j, column := range columns {
+			if column.GormName == name && !column.Ignored {
+				positions[i] = j
+			}
+		}
+		if positions[i] < 0 {
+			return false
+		}
+	}
+
+	option := "index:" + index.Name
+	if index.Unique {
+		option = "uniqueIndex:" + index.Name
+	}
+	if index.Class != "" {
+		option += ",class:" + index.Class
+	}
+	for i, j := range positions {
+		columnOption := option
+		if i < len(index.Lengths) && index.Lengths[i] > 0 {
+			columnOption += fmt.Sprintf(",length:%d", index.Lengths[i])
+		}
+		if len(index.Columns) > 1 {
+			columnOption += fmt.Sprintf(",priority:%d", i+1)
+		}
+		columns[j].GormOptions = append(columns[j].GormOptions, columnOption)
+	}
+	return true
+}
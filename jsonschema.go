@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var maxLengthPattern = regexp.MustCompile(`(?i)^(?:var)?char\((\d+)\)`)
+
+// JSONSchema is a JSON Schema (draft 2020-12) document or subschema.
+type JSONSchema struct {
+	Schema          string               `json:"$schema,omitempty"`
+	ID              string               `json:"$id,omitempty"`
+	Comment         string               `json:"$comment,omitempty"`
+	Ref             string               `json:"$ref,omitempty"`
+	Title           string               `json:"title,omitempty"`
+	Description     string               `json:"description,omitempty"`
+	Type            interface{}          `json:"type,omitempty"`
+	Format          string               `json:"format,omitempty"`
+	ContentEncoding string               `json:"contentEncoding,omitempty"`
+	MaxLength       int                  `json:"maxLength,omitempty"`
+	Enum            []interface{}        `json:"enum,omitempty"`
+	AnyOf           []*JSONSchema        `json:"anyOf,omitempty"`
+	Items           *JSONSchema          `json:"items,omitempty"`
+	Additional      *JSONSchema          `json:"additionalProperties,omitempty"`
+	Properties      JSONSchemaProperties `json:"properties,omitempty"`
+	Required        []string             `json:"required,omitempty"`
+}
+
+// JSONSchemaProperties are the properties of an object schema, encoded in
+// field order rather than sorted by name.
+type JSONSchemaProperties []JSONSchemaProperty
+
+// JSONSchemaProperty is a named property of an object schema.
+type JSONSchemaProperty struct {
+	Name   string
+	Schema *JSONSchema
+}
+
+func (properties JSONSchemaProperties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, property := range properties {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(property.Name)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := json.Marshal(property.Schema)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(schema)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// writeJSONSchemas writes a <table>.schema.json document per model to dir.
+func writeJSONSchemas(dir string, tables []Table, report *Report) {
+	if err := mkdirAll(dir); err != nil {
+		log.Fatalf("Failed to create JSON Schema directory: %v", err)
+	}
+	files := map[string]string{}
+	for _, table := range tables {
+		files[table.TableName] = table.DBTableName + ".schema.json"
+	}
+	for _, table := range tables {
+		data, err := json.MarshalIndent(buildJSONSchema(table, files), "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to encode JSON Schema of table %s: %v", table.DBTableName, err)
+		}
+		path := fmt.Sprintf("%s/%s", dir, files[table.TableName])
+		report.addTableFile(table.DBTableName, path, writeFile(path, append(data, '\n')))
+	}
+}
+
+// buildJSONSchema derives the JSON Schema of the JSON encoding of a model.
+// Properties are named like in the TypeScript interfaces, NOT NULL columns
+// are required, and associations reference the schemas of their models in
+// files.
+func buildJSONSchema(table Table, files map[string]string) *JSONSchema {
+	schema := &JSONSchema{
+		Schema:  "https://json-schema.org/draft/2020-12/schema",
+		ID:      files[table.TableName],
+		Comment: "Code generated by " + generatorName() + ". DO NOT EDIT.",
+		Title:   table.TableName,
+		Type:    "object",
+	}
+	enums := map[string]bool{}
+	for _, enum := range table.Enums {
+		enums[enum.Name] = true
+	}
+
+	for _, column := range table.Columns {
+		name, optional, ok := jsonName(column)
+		if !ok {
+			continue
+		}
+		property := jsonSchemaType(column.Type, files, enums)
+		if values := enumValues(column.DatabaseType); len(values) > 0 && !strings.HasPrefix(strings.ToLower(column.DatabaseType), "set(") && property.Type != nil {
+			for _, value := range values {
+				property.Enum = append(property.Enum, value)
+			}
+			if column.Nullable {
+				property.Enum = append(property.Enum, nil)
+			}
+		}
+		if match := maxLengthPattern.FindStringSubmatch(column.DatabaseType); match != nil && property.Enum == nil {
+			property.MaxLength, _ = strconv.Atoi(match[1])
+		}
+		if property.Format == "date-time" && strings.HasPrefix(strings.ToLower(column.DatabaseType), "date") && !strings.HasPrefix(strings.ToLower(column.DatabaseType), "datetime") {
+			property.Format = "date"
+		}
+		property.Description = column.Comment
+		schema.Properties = append(schema.Properties, JSONSchemaProperty{Name: name, Schema: property})
+		if !column.Nullable && !optional {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	for _, money := range table.MoneyFields {
+		schema.Properties = append(schema.Properties, JSONSchemaProperty{Name: money.Name, Schema: &JSONSchema{
+			Type: "object",
+			Properties: JSONSchemaProperties{
+				{Name: "amount", Schema: &JSONSchema{Type: "string"}},
+				{Name: "currency", Schema: &JSONSchema{Type: "string"}},
+			},
+			Required: []string{"amount", "currency"},
+		}})
+		schema.Required = append(schema.Required, money.Name)
+	}
+	for _, association := range table.Associations {
+		schema.Properties = append(schema.Properties, JSONSchemaProperty{Name: association.Name, Schema: jsonSchemaType(association.Type, files, enums)})
+	}
+	return schema
+}
+
+// jsonSchemaType returns the schema of the JSON encoding of a Go type.
+// Pointers, slices and maps encode nil as null.
+func jsonSchemaType(goType string, files map[string]string, enums map[string]bool) *JSONSchema {
+	switch {
+	case strings.HasPrefix(goType, "*"):
+		return nullableJSONSchema(jsonSchemaType(strings.TrimPrefix(goType, "*"), files, enums))
+	case goType == "[]byte" || goType == "[]uint8":
+		return &JSONSchema{Type: []string{"string", "null"}, ContentEncoding: "base64"}
+	case strings.HasPrefix(goType, "[]"):
+		return &JSONSchema{Type: []string{"array", "null"}, Items: jsonSchemaType(strings.TrimPrefix(goType, "[]"), files, enums)}
+	case strings.HasPrefix(goType, "map["):
+		_, valueType, _ := strings.Cut(strings.TrimPrefix(goType, "map["), "]")
+		return &JSONSchema{Type: []string{"object", "null"}, Additional: jsonSchemaType(valueType, files, enums)}
+	case enums[goType]:
+		return &JSONSchema{Type: "string"}
+	case files[goType] != "":
+		return &JSONSchema{Ref: files[goType]}
+	}
+
+	nullTypes := map[string]struct {
+		field     string
+		valueType *JSONSchema
+	}{
+		"sql.NullString":  {"String", &JSONSchema{Type: "string"}},
+		"sql.NullInt64":   {"Int64", &JSONSchema{Type: "integer"}},
+		"sql.NullInt32":   {"Int32", &JSONSchema{Type: "integer"}},
+		"sql.NullInt16":   {"Int16", &JSONSchema{Type: "integer"}},
+		"sql.NullFloat64": {"Float64", &JSONSchema{Type: "number"}},
+		"sql.NullBool":    {"Bool", &JSONSchema{Type: "boolean"}},
+		"sql.NullTime":    {"Time", &JSONSchema{Type: "string", Format: "date-time"}},
+	}
+	if null, ok := nullTypes[goType]; ok {
+		// database/sql null types have no JSON methods and encode as structs
+		return &JSONSchema{
+			Type:       "object",
+			Properties: JSONSchemaProperties{{Name: null.field, Schema: null.valueType}, {Name: "Valid", Schema: &JSONSchema{Type: "boolean"}}},
+			Required:   []string{null.field, "Valid"},
+		}
+	}
+
+	switch goType {
+	case "string", "EncryptedString", "decimal.Decimal":
+		return &JSONSchema{Type: "string"}
+	case "time.Time":
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	case "uuid.UUID":
+		return &JSONSchema{Type: "string", Format: "uuid"}
+	case "netip.Addr":
+		return &JSONSchema{Type: "string"}
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return &JSONSchema{Type: "integer"}
+	case "float32", "float64":
+		return &JSONSchema{Type: "number"}
+	case "bool":
+		return &JSONSchema{Type: "boolean"}
+	case "null.String":
+		return &JSONSchema{Type: []string{"string", "null"}}
+	case "null.Time", "gorm.DeletedAt":
+		return &JSONSchema{Type: []string{"string", "null"}, Format: "date-time"}
+	case "null.Int":
+		return &JSONSchema{Type: []string{"integer", "null"}}
+	case "null.Float":
+		return &JSONSchema{Type: []string{"number", "null"}}
+	case "null.Bool":
+		return &JSONSchema{Type: []string{"boolean", "null"}}
+	}
+	// JSON columns and unknown types accept any value
+	return &JSONSchema{}
+}
+
+// nullableJSONSchema extends a schema to accept null.
+func nullableJSONSchema(schema *JSONSchema) *JSONSchema {
+	switch t := schema.Type.(type) {
+	case string:
+		schema.Type = []string{t, "null"}
+	case nil:
+		if schema.Ref != "" {
+			return &JSONSchema{AnyOf: []*JSONSchema{schema, {Type: "null"}}}
+		}
+	}
+	return schema
+}
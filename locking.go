@@ -0,0 +1,31 @@
+package main
+
+import "strings"
+
+var lockingTemplate = `package models
+
+import "errors"
+
+// ErrStaleVersion is returned by UpdateWithVersion when the row was updated
+// since the model was read.
+var ErrStaleVersion = errors.New("models: row was updated concurrently")
+
+// ErrMissingPrimaryKey is returned by UpdateWithVersion when the primary key
+// of the model is not set, as the update would match every row of the
+// version.
+var ErrMissingPrimaryKey = errors.New("models: primary key is not set")
+`
+
+// versionColumn returns the optimistic locking version column of a table: the
+// first integer column matching a configured version column pattern.
+func versionColumn(config Config, tableName string, columns []Column) *Column {
+	for i, column := range columns {
+		if !matchColumn(config.VersionColumns, tableName, column.GormName) || column.PrimaryKey {
+			continue
+		}
+		if strings.HasPrefix(column.Type, "int") || strings.HasPrefix(column.Type, "uint") {
+			return &columns[i]
+		}
+	}
+	return nil
+}
@@ -1,186 +1,1845 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
 	"flag"
 	"fmt"
+	"go/format"
+	"go/token"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"text/template"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/jinzhu/inflection"
 
-	"github.com/joho/godotenv"
+	"gorm.io/driver/clickhouse"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 var modelTemplate = `package models
 
 {{if .ModelImports}}
 import (
-{{range .ModelImports}}
-	"{{.}}"
+{{- range $i, $group := .ImportGroups}}
+{{- if $i}}
 {{end}}
+{{- range $group}}
+	{{with index $.ImportAliases .}}{{.}} {{end}}"{{.}}"
+{{- end}}
+{{- end}}
 )
-{{end}}    
-
+{{end}}
 
+{{with .AuditOf}}
+// {{$.TableName}} records the history of the {{.}} model.
+{{- end}}
+{{- with .TimeComment}}
+// {{.}}
+{{- end}}
+{{- if .ReadOnly}}
+// {{.TableName}} is read-only, GORM never writes its fields to {{.DBTableName}}.
+{{- end}}
+{{- range .UntaggedIndexes}}
+// Index {{.}} is not in the gorm tags, as some of its columns are not in the model.
+{{- end}}
+{{- with .ShardPattern}}
+// {{$.TableName}} is the model of the sharded tables matching {{.}},
+// generated from {{$.DBTableName}}, which TableName returns. Query the other
+// shards with db.Table, e.g. db.Table("<shard>").Find(&rows).
+{{- end}}
 type {{.TableName}} struct {
 {{- range .Columns }}
-    {{.Name}} {{.Type}} ` + "`gorm:\"column:{{.GormName}}\"`" + `
+    {{- if .Comment }}
+    // {{.Comment}}
+    {{- end }}
+    {{.Name}} {{.Type}} ` + "`gorm:\"{{if .Ignored}}-{{else}}column:{{.GormName}}{{range .GormOptions}};{{.}}{{end}}{{end}}\"{{range .Tags}} {{.Key}}:\"{{.Value}}\"{{end}}`" + `{{with .TypeComment}} // {{.}}{{end}}
+{{- end }}
+{{- range .MoneyFields }}
+    {{.Name}} Money ` + "`gorm:\"embedded{{with .Prefix}};embeddedPrefix:{{.}}{{end}}\"`" + `
+{{- end }}
+{{- range .Associations }}
+    {{.Name}} {{.Type}} ` + "`gorm:\"{{range $i, $option := .GormOptions}}{{if $i}};{{end}}{{$option}}{{end}}\"`" + `
 {{- end }}
 }
 
+{{- if .TableNameMethod }}
+
 func ({{.TableName}}) TableName() string {
     return "{{.DBTableName}}"
 }
+{{- end }}
+{{- with .VersionColumn }}
+
+// UpdateWithVersion saves the model if its {{.GormName}} is unchanged since it was
+// read, and increments it. It returns ErrStaleVersion when another update
+// came first, and ErrMissingPrimaryKey when the primary key is not set.
+func (m *{{$.TableName}}) UpdateWithVersion(db *gorm.DB) error {
+    if {{range $i, $key := $.VersionKeys}}{{if $i}} || {{end}}reflect.ValueOf(m.{{$key}}).IsZero(){{end}} {
+        return ErrMissingPrimaryKey
+    }
+    version := m.{{.Name}}
+    m.{{.Name}}++
+    result := db.Model(m).
+        Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "{{.GormName}}"}, Value: version}).
+        Select("*").Omit(clause.Associations).
+        Updates(m)
+    if result.Error == nil && result.RowsAffected == 0 {
+        result.Error = ErrStaleVersion
+    }
+    if result.Error != nil {
+        m.{{.Name}} = version
+    }
+    return result.Error
+}
+{{- end }}
+{{- with .AuditUser }}
+{{- if .Hooks }}
+
+// BeforeCreate sets the created-by and updated-by columns to the current user.
+func (m *{{$.TableName}}) BeforeCreate(tx *gorm.DB) error {
+    if CurrentUserID == nil {
+        return nil
+    }
+    if userID, ok := CurrentUserID(tx.Statement.Context); ok {
+{{- range .CreatedBy }}
+        tx.Statement.SetColumn("{{.}}", userID)
+{{- end }}
+{{- range .UpdatedBy }}
+        tx.Statement.SetColumn("{{.}}", userID)
+{{- end }}
+    }
+    return nil
+}
+{{- if .UpdatedBy }}
+
+// BeforeUpdate sets the updated-by columns to the current user.
+func (m *{{$.TableName}}) BeforeUpdate(tx *gorm.DB) error {
+    if CurrentUserID == nil {
+        return nil
+    }
+    if userID, ok := CurrentUserID(tx.Statement.Context); ok {
+{{- range .UpdatedBy }}
+        tx.Statement.SetColumn("{{.}}", userID)
+{{- end }}
+    }
+    return nil
+}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- with .CloneEqual }}
+
+// Clone returns a deep copy of the model, including its associations.
+func (m {{$.TableName}}) Clone() {{$.TableName}} {
+    clone := m
+{{- range .CloneStatements }}
+    {{.}}
+{{- end }}
+    return clone
+}
+
+// Equal reports whether both models hold the same column values.
+func (m {{$.TableName}}) Equal(other {{$.TableName}}) bool {
+    return {{if not .EqualConditions}}true{{end}}{{range $i, $condition := .EqualConditions}}{{if $i}} &&
+        {{end}}{{$condition}}{{end}}
+}
+{{- end }}
+{{- if .StringFields }}
+
+// String returns a concise description of the model for logging and debugging.
+func (m {{.TableName}}) String() string {
+    return fmt.Sprintf("{{.TableName}}{ {{- range $i, $field := .StringFields}}{{if $i}}, {{end}}{{$field.Name}}: {{$field.Verb}}{{end -}} }"{{range .StringFields}}, m.{{.Name}}{{end}})
+}
+{{- end }}
+{{- if .Checks }}
+
+// Validate reports whether the model satisfies the CHECK constraints of the {{.DBTableName}} table.
+{{- range .Checks }}{{if not .Condition}}
+// Not validated: {{.Name}} ({{.Clause}})
+{{- end }}{{end}}
+func (m {{.TableName}}) Validate() error {
+{{- range .Checks }}{{if .Condition}}
+    if !({{.Condition}}) {
+        return errors.New({{printf "%q" .Message}})
+    }
+{{- end }}{{end}}
+    return nil
+}
+{{- end }}
+{{- range $enum := .Enums }}
+
+// {{.Name}} is a value of the {{$.DBTableName}}.{{.Column}} enum column.
+type {{.Name}} string
+
+const (
+{{- range .Constants }}
+    {{.Name}} {{$enum.Name}} = {{printf "%q" .Value}}
+{{- end }}
+)
+
+// Values returns the values of {{.Name}} in declaration order.
+func ({{.Name}}) Values() []{{.Name}} {
+    return []{{.Name}}{ {{- range $i, $constant := .Constants}}{{if $i}}, {{end}}{{$constant.Name}}{{end -}} }
+}
+
+// Valid reports whether e is one of the values of {{.Name}}.
+func (e {{.Name}}) Valid() bool {
+    switch e {
+    case {{range $i, $constant := .Constants}}{{if $i}}, {{end}}{{$constant.Name}}{{end}}:
+        return true
+    }
+    return false
+}
+
+// Scan reads the value from the database, rejecting unknown values.
+func (e *{{.Name}}) Scan(value interface{}) error {
+    var s string
+    switch v := value.(type) {
+    case string:
+        s = v
+    case []byte:
+        s = string(v)
+    default:
+        return fmt.Errorf("cannot scan %T into {{.Name}}", value)
+    }
+    if !{{.Name}}(s).Valid() {
+        return fmt.Errorf("invalid {{.Name}} %q", s)
+    }
+    *e = {{.Name}}(s)
+    return nil
+}
+
+// Value writes the value to the database, rejecting unknown values.
+func (e {{.Name}}) Value() (driver.Value, error) {
+    if !e.Valid() {
+        return nil, fmt.Errorf("invalid {{.Name}} %q", string(e))
+    }
+    return string(e), nil
+}
+{{- end }}
 `
 
 type Column struct {
-	Name     string
-	GormName string
-	Type     string
+	Name          string
+	GormName      string
+	Type          string
+	GormOptions   []string
+	Tags          []Tag
+	Nullable      bool
+	PrimaryKey    bool
+	AutoIncrement bool
+	HasDefault    bool
+	DatabaseType  string
+	Comment       string
+	Ignored       bool
+	Serializer    string
+	// TypeComment is the column type and nullability written after the field
+	TypeComment string
+}
+
+// Tag is a struct tag rendered after the gorm tag, e.g. json:"-".
+type Tag struct {
+	Key   string
+	Value string
 }
 
 type Table struct {
-	TableName    string
-	DBTableName  string
-	Columns      []Column
-	ModelImports []string
+	TableName   string
+	DBTableName string
+	// TimeComment documents how time columns are read, if there are any
+	TimeComment string
+	// AuditOf is the model whose changes an audit or history table records
+	AuditOf string
+	// TableNameMethod is false when the TableName method is omitted because
+	// GORM's default naming strategy already resolves the model to its table
+	TableNameMethod bool
+	Columns         []Column
+	MoneyFields     []MoneyField
+	Enums           []EnumType
+	Associations    []Association
+	Checks          []CheckConstraint
+	Indexes         []Index
+	StringFields    []StringField
+	CloneEqual      *CloneEqual
+	VersionColumn   *Column
+	// VersionKeys are the primary key fields UpdateWithVersion requires
+	VersionKeys   []string
+	AuditUser     *AuditUser
+	ModelImports  []string
+	ImportAliases map[string]string
+	// ReadOnly is set for views and the readOnlyTables of the config file,
+	// whose fields GORM never writes
+	ReadOnly bool
+	// UntaggedIndexes describe the indexes AutoMigrate needs tags for that
+	// could not be tagged, as some of their columns are not in the model
+	UntaggedIndexes []string
+	// ShardPattern is the pattern of shardPatterns matching the tables of a
+	// sharded family, whose model is generated from its last shard
+	ShardPattern string
+}
+
+// ImportGroups splits the imports of a model into standard library and other
+// packages, like goimports does.
+func (table Table) ImportGroups() [][]string {
+	var standard, other []string
+	for _, importPath := range table.ModelImports {
+		if strings.Contains(strings.Split(importPath, "/")[0], ".") {
+			other = append(other, importPath)
+		} else {
+			standard = append(standard, importPath)
+		}
+	}
+	var groups [][]string
+	for _, group := range [][]string{standard, other} {
+		if len(group) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
 }
 
 func main() {
 	destPath := flag.String("dest", ".", "Destination path for generated models")
-	envFile := flag.String("env", "", "Path to .env file")
+	driver := flag.String("driver", "", "Database driver (mysql, tidb, postgres, cockroach or clickhouse)")
+	var envFile envFiles
+	flag.Var(&envFile, "env", "Path to a .env file; repeat to load several, later files overriding earlier ones")
+	envPrefix := flag.String("env-prefix", "", "Prefix of the environment variables read, e.g. MYAPP_ for MYAPP_DB_USER")
+	configFile := flag.String("config", "", "Path to JSON config file")
+	profileName := flag.String("profile", "", "Profile of the config file with the connection settings, tables and destination to use, or comma-separated profiles to generate one after another")
+	packageFlag := flag.String("package", "models", "Package name of the generated files")
 	dbUser := flag.String("dbuser", "", "Database user")
 	dbPassword := flag.String("dbpassword", "", "Database password")
+	defaultsFile := flag.String("defaults-file", "", "MySQL option file to read the [client] credentials from (default: ~/.my.cnf)")
+	passwordStdin := flag.Bool("password-stdin", false, "Read the database password from the first line of stdin")
 	dbHost := flag.String("dbhost", "", "Database host")
 	dbPort := flag.String("dbport", "", "Database port")
 	dbName := flag.String("dbname", "", "Database name")
-	tables := flag.String("tables", "", "Comma-separated list of tables to generate models for")
+	tables := flag.String("tables", "", "Comma-separated list of tables or patterns, e.g. billing_*, to generate models for")
+	tablesFile := flag.String("tables-file", "", "File listing the tables to generate models for, one per line, instead of -tables")
+	all := flag.Bool("all", false, "Generate models for every table of the database instead of -tables")
+	includeSystemTables := flag.Bool("include-system-tables", false, "With -all, also generate models for the tables of migration tools and extensions, and for system schemas")
+	clean := flag.Bool("clean", false, "With -all, remove the generated files of models whose table no longer exists")
+	defaultTags := flag.Bool("default-tags", false, "Include the defaults of columns in gorm tags, e.g. default:CURRENT_TIMESTAMP")
+	collation := flag.Bool("collation", false, "Include column type and collation in gorm tags (MySQL and TiDB)")
+	checks := flag.Bool("checks", false, "Generate Validate() methods from CHECK constraints")
+	avroDir := flag.String("avro-dir", "", "Directory to write an Avro schema per table to")
+	avroTables := flag.String("avro-tables", "", "Comma-separated tables or patterns to write Avro schemas for (default: all)")
+	jsonSchemaDir := flag.String("json-schema-dir", "", "Directory to write a JSON Schema document per table to")
+	tsOut := flag.String("ts-out", "", "Path of a TypeScript file to write interfaces matching the JSON encoding of the models to")
+	sample := flag.Int("sample", 0, "Number of rows to sample to find text columns holding UUIDs, JSON or booleans")
+	sampleApply := flag.Bool("sample-apply", false, "Apply the types suggested by -sample instead of only reporting them")
+	softDelete := flag.Bool("soft-delete", false, "Map nullable deleted_at columns to gorm.DeletedAt and generate soft delete helpers")
+	bulk := flag.Bool("bulk", false, "Generate CreateMany functions inserting models in batches")
+	batchSize := flag.Int("batch-size", 0, "Rows per statement of the CreateMany functions (default 1000)")
+	filters := flag.Bool("filters", false, "Generate a filter struct per model from its indexed and time columns")
+	pagination := flag.Bool("pagination", false, "Generate offset and cursor pagination helpers per model")
+	debezium := flag.Bool("debezium", false, "Generate <Model>Change structs decoding Debezium change events of the models")
+	migrate := flag.Bool("migrate", false, "Generate AllModels and AutoMigrateAll, ordered by foreign key dependencies")
+	gormigrate := flag.Bool("gormigrate", false, "Generate gormigrate migrations creating the tables of the models in gormigrate.go")
+	typeComments := flag.Bool("type-comments", false, "Comment each field with its column type and nullability")
+	fieldOrder := flag.String("field-order", "", "Order of the generated fields (ordinal, alphabetical, grouped or fieldalign)")
+	fieldAlign := flag.Bool("fieldalign", false, "Order the generated fields to minimize padding, same as -field-order=fieldalign")
+	ignoreColumns := flag.String("ignore-columns", "", "Comma-separated columns to ignore, as table.column or column patterns, e.g. old_*")
+	ignoreMode := flag.String("ignore-mode", "", "How ignored columns are generated (skip or tag)")
+	enums := flag.Bool("enums", false, "Generate a string type with constants and Values(), Valid(), Scan() and Value() methods per enum column")
+	timeMode := flag.String("time-mode", "", "How datetime and timestamp columns are read (utc, local or string)")
+	nullStyle := flag.String("null-style", "", "Type used for nullable columns (pointer, sqlnull or guregu)")
+	var xmlTags, yamlTags, bsonTags, mapstructureTags namingFlag
+	flag.Var(&xmlTags, "xml-tags", "Add xml tags named with the given strategy (snake, camel, pascal or kebab)")
+	flag.Var(&yamlTags, "yaml-tags", "Add yaml tags named with the given strategy (snake, camel, pascal or kebab)")
+	flag.Var(&bsonTags, "bson-tags", "Add bson tags named with the given strategy (snake, camel, pascal or kebab)")
+	flag.Var(&mapstructureTags, "mapstructure-tags", "Add mapstructure tags named with the given strategy (snake, camel, pascal or kebab)")
+	cloneEqual := flag.Bool("clone-equal", false, "Generate Clone() and Equal() methods per model")
+	omitTableName := flag.Bool("omit-table-name", false, "Omit TableName() when GORM's default naming strategy resolves the model to its table")
+	tests := flag.Bool("tests", false, "Generate models_gen_test.go checking the models against the schema")
+	stringer := flag.Bool("stringer", false, "Generate a String() method per model")
+	queryBuilders := flag.Bool("query-builders", false, "Generate a type-safe query builder per model")
+	constructors := flag.Bool("constructors", false, "Generate a New<Model> constructor per model taking the NOT NULL columns without a default")
+	constructorStyle := flag.String("constructor-style", "", "Style of the -constructors: required, taking the required columns, or options, taking an option per column (default: required)")
+	builders := flag.Bool("builders", false, "Generate a fluent <Model>Builder per model whose Build checks the required fields are set")
+	getters := flag.Bool("getters", false, "Generate a <Model>Getter interface per model with a getter method per field")
+	shardHelpers := flag.Bool("shard-helpers", false, "Generate TableNameFor and InShard methods choosing a shard for the models of sharded families")
+	swag := flag.Bool("swag", false, "Annotate fields for swaggo with descriptions, formats, examples and enums")
+	bindingTagKinds := flag.String("binding-tags", "", "Comma-separated request binding tags to add (form, query, uri or param)")
+	createdByColumns := flag.String("created-by-columns", "", "Comma-separated columns holding the user who created a row, e.g. created_by")
+	updatedByColumns := flag.String("updated-by-columns", "", "Comma-separated columns holding the user who last updated a row, e.g. updated_by")
+	auditUserHooks := flag.Bool("audit-user-hooks", false, "Generate BeforeCreate/BeforeUpdate hooks filling created-by and updated-by columns from the context")
+	versionColumns := flag.String("version-columns", "", "Comma-separated optimistic locking version columns, e.g. version,lock_version")
+	tenantColumnName := flag.String("tenant-column", "", "Tenant column, e.g. tenant_id, to generate ScopeForTenant() for")
+	cacheRepositories := flag.Bool("cache-repositories", false, "With -tenant-repositories, also generate cache-aside decorators of the repositories")
+	tenantRepositories := flag.Bool("tenant-repositories", false, "Generate a repository per tenant table that always applies ScopeForTenant()")
+	skipAuditTables := flag.Bool("skip-audit-tables", false, "Skip <table>_audit and <table>_history tables of generated tables")
+	quiet := flag.Bool("quiet", false, "Do not print progress while generating")
+	backup := flag.String("backup-dir", "", "Directory to copy files to before they are overwritten, in a subdirectory per run")
+	incremental := flag.Bool("incremental", false, "Skip tables whose schema is unchanged since the last run, recorded in "+lockFileName)
+	reportJSON := flag.Bool("report-json", false, "Print the generation summary as JSON")
+	dryRunFlag := flag.Bool("dry-run", false, "Report the files that would be written, updated or removed without changing any")
+	diff := flag.Bool("diff", false, "Like -dry-run, and print a unified diff of every file that would change")
+	noColor := flag.Bool("no-color", false, "Print the summary and -diff without colors, as when stdout is not a terminal")
+	output := flag.String("output", "text", "Output format: text for the summary, or json for a line of JSON per file")
+	selftest := flag.String("selftest", "", "Path to a schema.sql file to generate and compile models against in a disposable MySQL container")
+	selftestImage := flag.String("selftest-image", "mysql:8.0", "MySQL image used by -selftest")
+	showVersion := flag.Bool("version", false, "Print the version and exit")
+	seedLimit := flag.Int("seed-limit", 100, "Maximum number of rows per table exported by the seed command, 0 for all")
+	migrationsDir := flag.String("migrations-dir", "migrations", "Directory the migrations command writes golang-migrate files to")
+	timeout := flag.Duration("timeout", 0, "Deadline of the queries reading the database, e.g. 5m (default: none)")
+	readWrite := flag.Bool("read-write", false, "Connect without making the session read-only and verifying it")
+	checkGrants := flag.Bool("check-grants", false, "Also verify the database user has no privileges that modify data")
+
+	// The seed subcommand exports rows, the migrations subcommand table
+	// definitions and the drift subcommand compares the models with the
+	// schema instead of generating models
+	command := ""
+	if len(os.Args) > 1 && (os.Args[1] == "seed" || os.Args[1] == "migrations" || os.Args[1] == "drift") {
+		command = os.Args[1]
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
 	flag.Parse()
 
-	// Load environment variables from .env file if it exists
-	if _, err := os.Stat(*envFile); err == nil {
-		err := godotenv.Load(*envFile)
+	if *showVersion {
+		fmt.Println("mysql-generate-gorm-models", versionString())
+		return
+	}
+
+	// Load environment variables from the .env files that exist
+	if err := loadEnvFiles(envFile); err != nil {
+		log.Fatalf("Error loading .env file: %v", err)
+	}
+
+	// Settings are taken from flags given on the command line, then from the
+	// profile, then from the environment
+	given := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+	// A tables file stands in for -tables, overriding profiles and the
+	// environment
+	if *tablesFile != "" {
+		if given["tables"] {
+			log.Fatal("-tables and -tables-file cannot be used together")
+		}
+		tableNames, err := readTablesFile(*tablesFile)
+		if err != nil {
+			log.Fatalf("Failed to read tables file %s: %v", *tablesFile, err)
+		}
+		if len(tableNames) == 0 {
+			log.Fatalf("Tables file %s lists no tables", *tablesFile)
+		}
+		*tables = strings.Join(tableNames, ",")
+		given["tables"] = true
+	}
+	if !given["config"] {
+		*configFile = os.Getenv(*envPrefix + "CONFIG_FILE")
+	}
+	var config Config
+	if *configFile != "" {
+		var err error
+		config, err = loadConfig(*configFile)
 		if err != nil {
-			log.Fatalf("Error loading .env file: %v", err)
+			log.Fatalf("Error loading config file: %v", err)
+		}
+	}
+
+	// Several profiles are generated one after another
+	if names := strings.Split(*profileName, ","); len(names) > 1 {
+		if *passwordStdin {
+			log.Fatal("-password-stdin cannot be used with several profiles, as only the first would read stdin")
+		}
+		runProfiles(command, names, config)
+		return
+	}
+
+	// A profile of the config file sets the settings not given as flags
+	if *profileName != "" {
+		profile, ok := config.Profiles[*profileName]
+		if !ok {
+			log.Fatalf("Profile %s is not defined in the config file", *profileName)
+		}
+		settings := map[string]string{
+			"driver":     profile.Driver,
+			"dbuser":     profile.User,
+			"dbpassword": profile.Password,
+			"dbhost":     profile.Host,
+			"dbport":     profile.Port,
+			"dbname":     profile.Name,
+			"tables":     strings.Join(profile.Tables, ","),
+			"dest":       profile.Dest,
+			"package":    profile.Package,
+		}
+		if profile.All {
+			settings["all"] = "true"
+		}
+		for name, value := range settings {
+			if value != "" && !given[name] {
+				flag.Set(name, value)
+				given[name] = true
+			}
+		}
+	}
+
+	environment := map[string]string{
+		"driver":     "DB_DRIVER",
+		"dbuser":     "DB_USER",
+		"dbpassword": "DB_PASSWORD",
+		"dbhost":     "DB_HOST",
+		"dbport":     "DB_PORT",
+		"dbname":     "DB_NAME",
+		"tables":     "TABLES",
+	}
+	for name, variable := range environment {
+		if value := os.Getenv(*envPrefix + variable); value != "" && !given[name] {
+			flag.Set(name, value)
 		}
 	}
+	if *driver == "" {
+		*driver = "mysql"
+	}
 
-	// Override environment variables with command-line arguments if provided
-	if *dbUser == "" {
-		*dbUser = os.Getenv("DB_USER")
+	// MySQL option files are the last source of credentials, as for the
+	// mysql client
+	if *driver == "mysql" || *driver == "tidb" {
+		path := *defaultsFile
+		if path == "" {
+			path = defaultOptionFile()
+		}
+		options, err := readOptionFile(path)
+		if err != nil && (*defaultsFile != "" || !os.IsNotExist(err)) {
+			log.Fatalf("Failed to read MySQL option file %s: %v", path, err)
+		}
+		for name, value := range options {
+			if value != "" && !given[name] && flag.Lookup(name).Value.String() == "" {
+				flag.Set(name, value)
+			}
+		}
+	}
+
+	if *clean && !*all {
+		log.Fatal("-clean requires -all, as only then are missing tables known to be dropped")
+	}
+	switch *output {
+	case "text":
+	case "json":
+		if *reportJSON {
+			log.Fatal("-report-json and -output json cannot be used together")
+		}
+
+	default:
+		log.Fatalf("Unsupported output format: %s", *output)
+	}
+	colorOutput = !*noColor && colorTerminal()
+	if *diff && (*reportJSON || *output == "json") {
+		log.Fatal("-diff cannot be used with -report-json or -output json, whose output it would interleave")
+	}
+
+	if *collation {
+		config.Collation = true
+	}
+	if *checks {
+		config.Checks = true
+	}
+	if *avroDir != "" {
+		config.AvroDir = *avroDir
+	}
+	if *avroTables != "" {
+		config.AvroTables = strings.Split(*avroTables, ",")
+	}
+	if *jsonSchemaDir != "" {
+		config.JSONSchemaDir = *jsonSchemaDir
+	}
+	if *tsOut != "" {
+		config.TSOut = *tsOut
+	}
+	if *sample > 0 {
+		config.Sample = *sample
+	}
+	if *sampleApply {
+		config.SampleApply = true
+	}
+	if *softDelete {
+		config.SoftDelete = true
+	}
+	if *bulk {
+		config.Bulk = true
+	}
+	if *batchSize > 0 {
+		config.BatchSize = *batchSize
+	}
+	if *filters {
+		config.Filters = true
+	}
+	if *pagination {
+		config.Pagination = true
+	}
+	if *debezium {
+		config.Debezium = true
+	}
+	if *migrate {
+		config.Migrate = true
+	}
+	if *gormigrate {
+		config.Gormigrate = true
+	}
+	if *typeComments {
+		config.TypeComments = true
+	}
+	if *fieldOrder != "" {
+		config.FieldOrder = *fieldOrder
+	}
+	if *fieldAlign {
+		config.FieldOrder = "fieldalign"
+	}
+	if *ignoreColumns != "" {
+		config.IgnoreColumns = strings.Split(*ignoreColumns, ",")
 	}
-	if *dbPassword == "" {
-		*dbPassword = os.Getenv("DB_PASSWORD")
+	if *ignoreMode != "" {
+		config.IgnoreMode = *ignoreMode
 	}
-	if *dbHost == "" {
-		*dbHost = os.Getenv("DB_HOST")
+	if *enums {
+		config.Enums = true
 	}
-	if *dbPort == "" {
-		*dbPort = os.Getenv("DB_PORT")
+	if *timeMode != "" {
+		config.TimeMode = *timeMode
 	}
-	if *dbName == "" {
-		*dbName = os.Getenv("DB_NAME")
+	if *nullStyle != "" {
+		config.NullStyle = *nullStyle
 	}
-	if *tables == "" {
-		*tables = os.Getenv("TABLES")
+	if xmlTags != "" {
+		config.XMLTags = string(xmlTags)
+	}
+	if yamlTags != "" {
+		config.YAMLTags = string(yamlTags)
+	}
+	if bsonTags != "" {
+		config.BSONTags = string(bsonTags)
+	}
+	if mapstructureTags != "" {
+		config.MapstructureTags = string(mapstructureTags)
+	}
+	if *swag {
+		config.Swag = true
+	}
+	if *queryBuilders {
+		config.QueryBuilders = true
+	}
+	if *constructors {
+		config.Constructors = true
+	}
+	if *constructorStyle != "" {
+		config.ConstructorStyle = *constructorStyle
+	}
+	if *builders {
+		config.Builders = true
+	}
+	if *getters {
+		config.Getters = true
+	}
+	if *shardHelpers {
+		config.ShardHelpers = true
+	}
+	if *stringer {
+		config.Stringer = true
+	}
+	if *cloneEqual {
+		config.CloneEqual = true
+	}
+	if *omitTableName {
+		config.OmitTableName = true
+	}
+	if *tests {
+		config.Tests = true
+	}
+	if *createdByColumns != "" {
+		config.CreatedByColumns = strings.Split(*createdByColumns, ",")
+	}
+	if *updatedByColumns != "" {
+		config.UpdatedByColumns = strings.Split(*updatedByColumns, ",")
+	}
+	if *auditUserHooks {
+		config.AuditUserHooks = true
+	}
+	if *versionColumns != "" {
+		config.VersionColumns = strings.Split(*versionColumns, ",")
+	}
+	if *tenantColumnName != "" {
+		config.TenantColumn = *tenantColumnName
+	}
+	if *tenantRepositories {
+		config.TenantRepositories = true
+	}
+	if *cacheRepositories {
+		config.CacheRepositories = true
+	}
+	if config.CacheRepositories && !config.TenantRepositories {
+		log.Fatalf("-cache-repositories requires -tenant-repositories")
+	}
+	if *skipAuditTables {
+		config.SkipAuditTables = true
+	}
+	if *includeSystemTables {
+		config.IncludeSystemTables = true
+	}
+	if *defaultTags {
+		config.DefaultTags = true
+	}
+	if *quiet {
+		config.Quiet = true
+	}
+	if *incremental {
+		config.Incremental = true
+	}
+	if *checkGrants {
+		config.CheckGrants = true
+	}
+	if *backup != "" {
+		config.BackupDir = *backup
+	}
+	backupDir = config.BackupDir
+	if !token.IsIdentifier(*packageFlag) {
+		log.Fatalf("Invalid package name: %s", *packageFlag)
+	}
+	modelPackage = *packageFlag
+	if *bindingTagKinds != "" {
+		config.BindingTags = strings.Split(*bindingTagKinds, ",")
+	}
+	for _, kind := range config.BindingTags {
+		if kind != "form" && kind != "query" && kind != "uri" && kind != "param" {
+			log.Fatalf("Unsupported binding tag: %s", kind)
+		}
+	}
+	for _, strategy := range []string{config.XMLTags, config.YAMLTags, config.BSONTags, config.MapstructureTags} {
+		if !validNamingStrategy(strategy) {
+			log.Fatalf("Unsupported naming strategy: %s", strategy)
+		}
+	}
+	for _, money := range config.Money {
+		if _, ok := moneyPrefix(money); !ok {
+			log.Fatalf("Money columns %s and %s must be named <prefix>amount and <prefix>currency", money.Amount, money.Currency)
+		}
+	}
+	switch config.FieldOrder {
+	case "", "ordinal", "alphabetical", "grouped", "fieldalign":
+	default:
+		log.Fatalf("Unsupported field order: %s", config.FieldOrder)
+	}
+	switch config.IgnoreMode {
+	case "", "skip", "tag":
+	default:
+		log.Fatalf("Unsupported ignore mode: %s", config.IgnoreMode)
+	}
+	switch config.TimeMode {
+	case "":
+		config.TimeMode = "utc"
+	case "utc", "local", "string":
+	default:
+		log.Fatalf("Unsupported time mode: %s", config.TimeMode)
+	}
+	switch config.NullStyle {
+	case "":
+		config.NullStyle = "pointer"
+	case "pointer", "sqlnull", "guregu":
+	default:
+		log.Fatalf("Unsupported null style: %s", config.NullStyle)
+	}
+	switch config.ConstructorStyle {
+	case "":
+		config.ConstructorStyle = "required"
+	case "required", "options":
+	default:
+		log.Fatalf("Unsupported constructor style: %s", config.ConstructorStyle)
+	}
+	for column, serializer := range config.Serializers {
+		if serializer != "gob" && serializer != "unixtime" {
+			log.Fatalf("Unsupported serializer %s of column %s", serializer, column)
+		}
+	}
+	if err := registerCustomTypes(&config); err != nil {
+		log.Fatalf("Invalid custom type: %v", err)
+	}
+	for _, pattern := range config.ShardPatterns {
+		if !validShardPattern(pattern) {
+			log.Fatalf("Invalid shard pattern %s\nhint: start it with the table name of the family and match the shards with wildcards, e.g. \"events_*\"", pattern)
+		}
+	}
+
+	if *selftest != "" {
+		if err := runSelftest(*selftest, *selftestImage, config); err != nil {
+			log.Fatalf("Self-test failed: %v", err)
+		}
+		return
+	}
+
+	// Passwords read from stdin or the terminal stay out of the shell history
+	// and process listings
+	if *passwordStdin {
+		if given["dbpassword"] {
+			log.Fatal("-password-stdin and -dbpassword cannot be used together")
+		}
+		password, err := readPasswordFrom(os.Stdin)
+		if err != nil {
+			log.Fatalf("Failed to read the password from stdin: %v", err)
+		}
+		*dbPassword = password
+	} else if *dbPassword == "" {
+		password, ok, err := promptPassword()
+		if err != nil {
+			log.Fatalf("Failed to read the password: %v", err)
+		}
+		if ok {
+			*dbPassword = password
+		}
 	}
 
-	if *dbUser == "" || *dbPassword == "" || *dbName == "" || *tables == "" {
+	if *dbUser == "" || *dbPassword == "" || *dbName == "" || *tables == "" && !*all {
 		log.Fatal("Database user, password, name, and tables are required")
 	}
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", *dbUser, *dbPassword, *dbHost, *dbPort, *dbName)
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	// A dry run writes nothing, and -diff prints the changes it would make
+	// before the summary
+	dryRun = *dryRunFlag || *diff
+	if *diff {
+		diffOut = os.Stdout
+	}
+
+	// Pre-generation hooks may change the schema, e.g. by running pending
+	// migrations, so they run before it is read
+	if command == "" && !dryRun {
+		if err := runHooks(config.PreHooks, "."); err != nil {
+			log.Fatalf("Pre-generation hook %v", err)
+		}
+	}
+
+	// Every query runs with a context cancelled after -timeout or on SIGINT,
+	// so a slow catalog query cannot hang the run. A second SIGINT exits at
+	// once.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	go func() {
+		<-interrupts
+		signal.Stop(interrupts)
+		fmt.Fprintln(os.Stderr, "Interrupted, cancelling the running query")
+		cancel()
+	}()
+	if *timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, *timeout)
+		defer cancelTimeout()
+	}
+
+	// Connect with the time parameters the generated models expect, and
+	// unless -read-write is given with a read-only session
+	timeParams := timeDSNParams(*driver, config.TimeMode)
+	connect := func(readOnlyParam string) (*gorm.DB, error) {
+		var params []string
+		for _, param := range []string{timeParams, readOnlyParam} {
+			if param != "" {
+				params = append(params, param)
+			}
+		}
+		var dialector gorm.Dialector
+		switch *driver {
+		case "mysql", "tidb":
+			dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", *dbUser, *dbPassword, *dbHost, *dbPort, *dbName)
+			if len(params) > 0 {
+				dsn += "?" + strings.Join(params, "&")
+			}
+			conn, err := openContext(ctx, "mysql", dsn)
+			if err != nil {
+				return nil, err
+			}
+			// TiDB reports a MySQL-compatible version string with a TiDB suffix,
+			// so skip the version-based feature detection of the MySQL driver.
+			dialector = mysql.New(mysql.Config{DSN: dsn, Conn: conn, SkipInitializeWithVersion: *driver == "tidb"})
+		case "postgres", "cockroach":
+			dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable", *dbHost, *dbUser, *dbPassword, *dbName, *dbPort)
+			if len(params) > 0 {
+				dsn += " " + strings.Join(params, " ")
+			}
+			dialector = postgres.Open(dsn)
+		case "clickhouse":
+			dsn := fmt.Sprintf("clickhouse://%s:%s@%s:%s/%s", *dbUser, *dbPassword, *dbHost, *dbPort, *dbName)
+			if len(params) > 0 {
+				dsn += "?" + strings.Join(params, "&")
+			}
+			conn, err := openContext(ctx, "clickhouse", dsn)
+			if err != nil {
+				return nil, err
+			}
+			dialector = clickhouse.New(clickhouse.Config{DSN: dsn, Conn: conn})
+		default:
+			log.Fatalf("Unsupported driver: %s", *driver)
+		}
+		db, err := gorm.Open(dialector, &gorm.Config{DisableAutomaticPing: true})
+		if err != nil {
+			return nil, err
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return nil, err
+		}
+		return db.WithContext(ctx), nil
+	}
+	readOnlyParam := ""
+	if !*readWrite {
+		readOnlyParam = readOnlyDSNParam(*driver, false)
+	}
+	db, err := connect(readOnlyParam)
+	legacyReadOnly := false
+	if err != nil && readOnlyParam != "" && isUnknownReadOnlyVariable(err) {
+		legacyReadOnly = true
+		db, err = connect(readOnlyDSNParam(*driver, true))
+	}
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Fatalf("Failed to connect to database: %s", explain(err))
+	}
+	if !*readWrite {
+		if err := verifyReadOnly(db, *driver, legacyReadOnly, config.CheckGrants); err != nil {
+			log.Fatalf("Refusing to read the schema, %v; pass -read-write to proceed anyway", err)
+		}
 	}
 
 	tableNames := strings.Split(*tables, ",")
+	if *all {
+		if !config.IncludeSystemTables && isSystemSchema(*driver, *dbName) {
+			log.Fatalf("%s is a system schema; pass -include-system-tables to generate models for its tables", *dbName)
+		}
+		tableNames, err = db.Migrator().GetTables()
+		if err != nil {
+			log.Fatalf("Failed to list tables: %s", explain(err))
+		}
+		if !config.IncludeSystemTables {
+			tableNames = withoutFrameworkTables(tableNames)
+		}
+		sort.Strings(tableNames)
+	} else if slices.ContainsFunc(tableNames, isTablePattern) {
+		// Patterns of -tables are expanded against the tables of the database
+		databaseTables, err := db.Migrator().GetTables()
+		if err != nil {
+			log.Fatalf("Failed to list tables: %s", explain(err))
+		}
+		tableNames, err = expandTables(tableNames, databaseTables)
+		if err != nil {
+			log.Fatalf("Failed to select tables: %v", err)
+		}
+	}
+	report := Report{DryRun: dryRun}
+	switch command {
+	case "seed":
+		seed(db, *driver, tableNames, *destPath, *seedLimit, config, &report)
+	case "migrations":
+		migrations(db, *driver, tableNames, *migrationsDir, &report)
+	case "drift":
+		drift(db, *driver, tableNames, *destPath, config, &report)
+	default:
+		locked := loadLock(*destPath)
+		mergeStore = filepath.Join(*destPath, mergeStoreName)
+		for path, hash := range locked.files() {
+			mergeBases[filepath.Clean(lockedFilePath(*destPath, path))] = hash
+		}
+		lock := generate(db, *driver, tableNames, *destPath, locked, config, &report)
+		if *clean {
+			cleanOrphans(*destPath, locked, lock, &report)
+		}
+		writeLock(*destPath, lock, &report)
+		pruneMergeStore(lock)
+	}
+	switch {
+	case *output == "json":
+		if err := report.PrintFiles(os.Stdout); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+	case *reportJSON:
+		if err := report.PrintJSON(os.Stdout); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+	default:
+		report.Print(os.Stdout)
+	}
+	if command == "drift" && len(report.Drift) > 0 {
+		os.Exit(1)
+	}
+	if len(report.Conflicts) > 0 {
+		// Hooks formatting or building the models would fail on the markers
+		os.Exit(1)
+	}
+	if command == "" && !dryRun {
+		if err := runHooks(config.PostHooks, *destPath); err != nil {
+			log.Fatalf("Post-generation hook %v", err)
+		}
+	}
+}
+
+// generate writes the models of the given tables, and the files generated
+// alongside them, to destPath, recording what it did in report. It returns
+// the lock recording the run, given the lock of the previous run.
+func generate(db *gorm.DB, driver string, tableNames []string, destPath string, locked Lock, config Config, report *Report) Lock {
+	// Only keep foreign keys between tables we generate, so associations
+	// always reference a model that exists
+	allForeignKeys, err := loadForeignKeys(db, driver)
+	if err != nil {
+		log.Fatalf("Failed to get foreign keys: %s", explain(err))
+	}
+	// Sharded families get a single model, generated from their last shard
+	tableNames = collapseShards(config.ShardPatterns, tableNames)
+	requested := map[string]bool{}
 	for _, tableName := range tableNames {
-		generateModel(db, tableName, *destPath)
+		requested[tableName] = true
 	}
+
+	// Audit and history tables are skipped, or linked to their base model
+	auditOf := map[string]string{}
+	var modelTables []string
+	for _, tableName := range tableNames {
+		if base, ok := auditBaseTable(tableName, requested); ok {
+			if config.SkipAuditTables {
+				continue
+			}
+			auditOf[tableName] = modelName(base)
+		}
+		modelTables = append(modelTables, tableName)
+	}
+	generated := map[string]bool{}
+	for _, tableName := range modelTables {
+		generated[tableName] = true
+	}
+	// Shards get no associations, which GORM would load from a single shard
+	var foreignKeys []ForeignKey
+	for _, fk := range allForeignKeys {
+		_, _, fromShard := shardFamily(config.ShardPatterns, fk.Table)
+		_, _, toShard := shardFamily(config.ShardPatterns, fk.ReferencedTable)
+		if generated[fk.Table] && generated[fk.ReferencedTable] && !fromShard && !toShard {
+			foreignKeys = append(foreignKeys, fk)
+		}
+	}
+	if err := markUniqueForeignKeys(db, foreignKeys); err != nil {
+		log.Fatalf("Failed to get unique constraints: %v", err)
+	}
+
+	// Likewise only keep polymorphic associations to generated models
+	var polymorphics []PolymorphicConfig
+	for _, polymorphic := range config.Polymorphic {
+		if generated[polymorphic.Table] {
+			polymorphics = append(polymorphics, polymorphic)
+		}
+	}
+	config.Polymorphic = polymorphics
+
+	// Incremental generation skips the tables whose schema is unchanged
+	// since the lock file was written, reusing their models
+	lock := Lock{
+		Key:     lockKey(db, driver, modelTables, foreignKeys, config),
+		Version: versionString(),
+		Driver:  driver,
+		Package: modelPackage,
+		Options: lockOptions(config),
+		Tables:  map[string]LockedTable{},
+	}
+	reuse := config.Incremental && locked.Key == lock.Key
+	var generatedTables []Table
+	progress := newProgress(len(modelTables), config.Quiet)
+	for _, tableName := range modelTables {
+		progress.Start(tableName)
+		hash := schemaHash(db, driver, tableName)
+		entry, ok := locked.Tables[tableName]
+		if _, err := os.Stat(fmt.Sprintf("%s/%s.go", destPath, entry.Model.TableName)); reuse && ok && entry.Schema == hash && err == nil {
+			report.Skipped = append(report.Skipped, tableName)
+			report.addTableFile(tableName, fmt.Sprintf("%s/%s.go", destPath, entry.Model.TableName), fileSkipped)
+		} else {
+			entry = LockedTable{Schema: hash, Model: generateModel(db, driver, tableName, destPath, foreignKeys, auditOf[tableName], config, report)}
+		}
+		lock.Tables[tableName] = entry
+		generatedTables = append(generatedTables, entry.Model)
+	}
+	progress.Finish()
+	writeTenantFiles(config, generatedTables, destPath, report)
+	if userType, ok := currentUserType(generatedTables); ok && config.AuditUserHooks {
+		currentUser := CurrentUser{
+			Type:    userType,
+			Columns: strings.Join(append(append([]string{}, config.CreatedByColumns...), config.UpdatedByColumns...), ", "),
+		}
+		path := fmt.Sprintf("%s/currentuser.go", destPath)
+		report.addFile(path, writeTemplate(currentUserTemplate, path, currentUser))
+	}
+	for _, table := range generatedTables {
+		if len(table.MoneyFields) > 0 {
+			path := fmt.Sprintf("%s/money.go", destPath)
+			report.addFile(path, writeTemplate(moneyTemplate, path, nil))
+			break
+		}
+	}
+	for _, table := range generatedTables {
+		if table.VersionColumn != nil {
+			path := fmt.Sprintf("%s/locking.go", destPath)
+			report.addFile(path, writeTemplate(lockingTemplate, path, nil))
+			break
+		}
+	}
+	if usesEncryptedString(generatedTables) {
+		encrypted := Encrypted{Crypter: config.Crypter, ImportAliases: importAliases(config.Imports)}
+		if qualifier := typeQualifier(config.Crypter); qualifier != "" {
+			var ok bool
+			encrypted.CrypterImport, ok = config.Imports[qualifier]
+			if !ok {
+				log.Fatalf("No import configured for crypter %s", config.Crypter)
+			}
+		}
+		path := fmt.Sprintf("%s/encrypted.go", destPath)
+		report.addFile(path, writeTemplate(encryptedTemplate, path, encrypted))
+	}
+	if config.SoftDelete && usesSoftDelete(config, generatedTables) {
+		path := fmt.Sprintf("%s/softdelete.go", destPath)
+		report.addFile(path, writeTemplate(softDeleteTemplate, path, softDeleteColumnName(config)))
+	}
+	if usesNetIP(generatedTables) {
+		path := fmt.Sprintf("%s/netip.go", destPath)
+		report.addFile(path, writeTemplate(netipTemplate, path, nil))
+	}
+	if config.AvroDir != "" {
+		writeAvroSchemas(config.AvroDir, config.AvroTables, config.AvroNamespace, generatedTables, report)
+	}
+	if config.JSONSchemaDir != "" {
+		writeJSONSchemas(config.JSONSchemaDir, generatedTables, report)
+	}
+	if config.TSOut != "" {
+		report.addFile(config.TSOut, writeTypeScript(config.TSOut, generatedTables))
+	}
+	if config.Bulk {
+		path := fmt.Sprintf("%s/bulk.go", destPath)
+		report.addFile(path, writeTemplate(bulkTemplate, path, buildBulk(driver, generatedTables, config.BatchSize)))
+	}
+	if config.Debezium {
+		writeChanges(destPath, generatedTables, config.DebeziumDecimalHandling, report)
+	}
+	if config.Migrate || config.Gormigrate {
+		models, err := orderModels(generatedTables, foreignKeys)
+		if err != nil {
+			log.Fatalf("Failed to order models for migrations: %v", err)
+		}
+		if config.Migrate {
+			path := fmt.Sprintf("%s/migrate.go", destPath)
+			report.addFile(path, writeTemplate(migrateTemplate, path, models))
+		}
+		if config.Gormigrate {
+			path := fmt.Sprintf("%s/gormigrate.go", destPath)
+			report.addFile(path, writeTemplate(gormigrateTemplate, path, buildGormigrateMigrations(generatedTables, models)))
+		}
+	}
+	if config.Tests {
+		path := fmt.Sprintf("%s/models_gen_test.go", destPath)
+		report.addFile(path, writeTemplate(modelTestsTemplate, path, buildModelTests(driver, generatedTables)))
+	}
+	lock.recordFiles(destPath, report)
+	return lock
 }
 
-func generateModel(db *gorm.DB, tableName, destPath string) {
+// generateModel writes the model of a table, and its query builder, filter,
+// pagination helpers, constructor, builder, getters and shard helpers if
+// enabled, to destPath.
+func generateModel(db *gorm.DB, driver, tableName, destPath string, foreignKeys []ForeignKey, auditOf string, config Config, report *Report) Table {
+	report.table = tableName
+	defer func() { report.table = "" }()
+	table := buildModel(db, driver, tableName, foreignKeys, auditOf, config, report)
+
+	text, err := tableTemplate(config, tableName)
+	if err != nil {
+		log.Fatalf("Failed to read template for table %s: %v", tableName, err)
+	}
+	path := fmt.Sprintf("%s/%s.go", destPath, table.TableName)
+	report.addFile(path, writeTemplate(text, path, table))
+	if config.QueryBuilders {
+		path := fmt.Sprintf("%s/%sQuery.go", destPath, table.TableName)
+		report.addFile(path, writeTemplate(queryTemplate, path, buildQueryBuilder(table)))
+	}
+	if config.Filters {
+		path := fmt.Sprintf("%s/%sFilter.go", destPath, table.TableName)
+		report.addFile(path, writeTemplate(filterTemplate, path, buildFilter(table)))
+	}
+	if config.Pagination {
+		path := fmt.Sprintf("%s/%sPagination.go", destPath, table.TableName)
+		report.addFile(path, writeTemplate(paginationTemplate, path, buildPagination(table, config.PaginationColumns[tableName], report)))
+	}
+	if config.Constructors {
+		text := constructorTemplate
+		if config.ConstructorStyle == "options" {
+			text = optionsConstructorTemplate
+		}
+		path := fmt.Sprintf("%s/%sConstructor.go", destPath, table.TableName)
+		report.addFile(path, writeTemplate(text, path, buildConstructor(table, config.ConstructorStyle)))
+	}
+	if config.Builders {
+		if builder, ok := buildBuilder(table); ok {
+			path := fmt.Sprintf("%s/%sBuilder.go", destPath, table.TableName)
+			report.addFile(path, writeTemplate(builderTemplate, path, builder))
+		} else {
+			report.warnf("%s has a Build column, %sBuilder is not generated", tableName, table.TableName)
+		}
+	}
+	if config.Getters {
+		if getters, ok := buildGetters(table); ok {
+			path := fmt.Sprintf("%s/%sGetter.go", destPath, table.TableName)
+			report.addFile(path, writeTemplate(gettersTemplate, path, getters))
+		} else {
+			report.warnf("%s has a field named like the getter of another, e.g. GetEmail for Email, %sGetter is not generated", tableName, table.TableName)
+		}
+	}
+	if config.ShardHelpers && table.ShardPattern != "" {
+		if helpers, ok := buildShardHelpers(table); ok {
+			path := fmt.Sprintf("%s/%sShard.go", destPath, table.TableName)
+			report.addFile(path, writeTemplate(shardTemplate, path, helpers))
+		} else {
+			report.warnf("%s has a TableNameFor or InShard field, %sShard is not generated", tableName, table.TableName)
+		}
+	}
+	return table
+}
+
+// buildModel reads the columns, constraints and indexes of a table and
+// derives its model.
+func buildModel(db *gorm.DB, driver, tableName string, foreignKeys []ForeignKey, auditOf string, config Config, report *Report) Table {
 	var columns []Column
 	var modelImports []string
 	columnTypes, err := db.Migrator().ColumnTypes(tableName)
 	if err != nil {
-		log.Fatalf("Failed to get columns for table %s: %v", tableName, err)
+		log.Fatalf("Failed to get columns for table %s: %s", tableName, explainTable(db, tableName, err))
+	}
+
+	hiddenColumns := map[string]bool{}
+	if driver == "cockroach" {
+		hiddenColumns, err = cockroachHiddenColumns(db, tableName)
+		if err != nil {
+			log.Fatalf("Failed to get hidden columns for table %s: %v", tableName, err)
+		}
 	}
 
+	name := modelName(tableName)
+	family, shardPattern, sharded := shardFamily(config.ShardPatterns, tableName)
+	if sharded {
+		name = modelName(family)
+	}
+
+	readOnly := matchAny(config.ReadOnlyTables, tableName)
+	if !readOnly {
+		readOnly, err = isView(db, driver, tableName)
+		if err != nil {
+			log.Fatalf("Failed to get the type of table %s: %v", tableName, err)
+		}
+	}
+
+	autoRandomBits := 0
+	if driver == "tidb" {
+		autoRandomBits, err = tidbAutoRandomBits(db, tableName)
+		if err != nil {
+			log.Fatalf("Failed to get AUTO_RANDOM info for table %s: %v", tableName, err)
+		}
+	}
+
+	onUpdate := map[string]bool{}
+	if config.DefaultTags && (driver == "mysql" || driver == "tidb") {
+		onUpdate, err = mysqlOnUpdateColumns(db, tableName)
+		if err != nil {
+			log.Fatalf("Failed to get ON UPDATE columns for table %s: %v", tableName, err)
+		}
+	}
+
+	collations := map[string]string{}
+	if config.Collation && (driver == "mysql" || driver == "tidb") {
+		collations, err = mysqlColumnCollations(db, tableName)
+		if err != nil {
+			log.Fatalf("Failed to get collations for table %s: %v", tableName, err)
+		}
+	}
+
+	postgresEnums := map[string][]string{}
+	if config.Enums && (driver == "postgres" || driver == "cockroach") {
+		postgresEnums, err = loadPostgresEnums(db)
+		if err != nil {
+			log.Fatalf("Failed to get enum types: %v", err)
+		}
+	}
+
+	included, includeOnly := config.IncludeColumns[tableName]
+	if includeOnly {
+		for _, name := range included {
+			found := false
+			for _, columnType := range columnTypes {
+				found = found || columnType.Name() == name
+			}
+			if !found {
+				report.warnf("Included column %s.%s does not exist", tableName, name)
+			}
+		}
+	}
+
+	samples := map[string][]string{}
+	if config.Sample > 0 {
+		samples, err = sampleValues(db, tableName, config.Sample)
+		if err != nil {
+			log.Fatalf("Failed to sample rows of table %s: %v", tableName, err)
+		}
+	}
+
+	aliases := importAliases(config.Imports)
+	hasTimeColumns := false
+	var enums []EnumType
 	for _, columnType := range columnTypes {
-		modelColumnType := columnType.DatabaseTypeName()
-		// Add special handling for datetime columns
-		switch columnType.DatabaseTypeName() {
-		case "datetime", "timestamp", "date", "time":
-			modelColumnType = "time.Time"
-			if !strings.Contains(strings.Join(modelImports, ","), "time") {
-				modelImports = append(modelImports, "time")
-			}
-		case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
-			modelColumnType = "int"
-		case "float", "double", "real":
-			modelColumnType = "float64"
-		case "decimal", "numeric":
-			modelColumnType = "string" // or use a custom decimal type
-		case "char", "varchar", "tinytext", "text", "mediumtext", "longtext":
-			modelColumnType = "string"
-		case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
-			modelColumnType = "[]byte"
-		case "bit":
-			modelColumnType = "[]uint8"
-		case "bool", "boolean":
-			modelColumnType = "bool"
-		case "json":
-			modelColumnType = "json.RawMessage"
-			if !strings.Contains(strings.Join(modelImports, ","), "encoding/json") {
-				modelImports = append(modelImports, "encoding/json")
-			}
-		case "enum", "set":
-			modelColumnType = "string"
+		if hiddenColumns[columnType.Name()] {
+			continue
+		}
+		ignored := matchColumn(config.IgnoreColumns, tableName, columnType.Name())
+		if ignored && config.IgnoreMode != "tag" {
+			continue
+		}
+		if includeOnly && !containsString(included, columnType.Name()) {
+			// Primary keys are always kept so saves and deletes target the row
+			if primaryKey, _ := columnType.PrimaryKey(); !primaryKey {
+				nullable, _ := columnType.Nullable()
+				_, hasDefault := columnType.DefaultValue()
+				autoIncrement, _ := columnType.AutoIncrement()
+				if !nullable && !hasDefault && !autoIncrement {
+					report.warnf("%s.%s is NOT NULL without a default and not included, creating %s rows will fail", tableName, columnType.Name(), name)
+				}
+				continue
+			}
+		}
+
+		var modelColumnType, importPath string
+		var mapped bool
+		switch driver {
+		case "postgres", "cockroach":
+			modelColumnType, importPath, mapped = postgresColumnType(columnType.DatabaseTypeName())
+		case "clickhouse":
+			modelColumnType, importPath, mapped = clickhouseColumnType(columnType.DatabaseTypeName())
 		default:
-			modelColumnType = "string" // default to string for any other types
+			modelColumnType, importPath, mapped = mysqlColumnType(columnType.DatabaseTypeName())
+		}
+
+		if config.Enums {
+			var values []string
+			if driver == "postgres" || driver == "cockroach" {
+				values = postgresEnums[columnType.DatabaseTypeName()]
+			} else if fullType, ok := columnType.ColumnType(); ok && (driver == "mysql" || driver == "tidb") && strings.HasPrefix(strings.ToLower(fullType), "enum(") {
+				values = enumValues(fullType)
+			}
+			if _, overridden := columnTypeOverride(config, tableName, columnType.Name()); len(values) > 0 && !overridden {
+				if enum, ok := buildEnumType(name, camelCase(columnType.Name()), columnType.Name(), values); ok {
+					modelColumnType, importPath, mapped = enum.Name, "", true
+					enums = append(enums, enum)
+				}
+			}
+		}
+		timeColumn := strings.Contains(modelColumnType, "time.Time")
+		binaryColumn := modelColumnType == "[]byte"
+		if timeColumn {
+			hasTimeColumns = true
+		}
+		modelColumnType, importPath = timeColumnType(modelColumnType, importPath, config.TimeMode)
+
+		primaryKey, _ := columnType.PrimaryKey()
+		nullable, _ := columnType.Nullable()
+		defaultValue, hasDefault := columnType.DefaultValue()
+		autoIncrement, _ := columnType.AutoIncrement()
+		if driver == "cockroach" && cockroachAutoIncrement(columnType) {
+			autoIncrement = true
+		}
+		var gormType string
+		if override, ok := columnTypeOverride(config, tableName, columnType.Name()); ok {
+			if custom, ok := config.Types[override]; ok {
+				override, gormType = custom.GoType, custom.GormType
+			}
+			modelColumnType, importPath = override, ""
+			if qualifier := typeQualifier(override); qualifier != "" {
+				importPath, ok = config.Imports[qualifier]
+				if !ok {
+					log.Fatalf("No import configured for type %s of column %s.%s\nhint: add its package to \"imports\" in the config file, e.g. \"%s\": \"<import path>\"", override, tableName, columnType.Name(), qualifier)
+				}
+			}
+		} else if matchColumn(config.Encrypted, tableName, columnType.Name()) {
+			modelColumnType, importPath = encryptedType(nullable && !primaryKey), ""
+		} else {
+			if config.Sample > 0 && mapped && modelColumnType == "string" && textColumn(columnType.DatabaseTypeName()) {
+				if goType, sampledImport, holds, ok := sampledType(samples[columnType.Name()]); ok {
+					report.Samples = append(report.Samples, Sample{Table: tableName, Column: columnType.Name(), Holds: holds, GoType: goType, Values: len(samples[columnType.Name()]), Applied: config.SampleApply})
+					if config.SampleApply {
+						modelColumnType, importPath = goType, sampledImport
+					}
+				}
+			}
+			if !mapped {
+				report.Fallbacks = append(report.Fallbacks, Fallback{Table: tableName, Column: columnType.Name(), DatabaseType: columnType.DatabaseTypeName()})
+			}
+			if nullable && !primaryKey {
+				modelColumnType, importPath = nullableType(modelColumnType, importPath, config.NullStyle)
+			}
+			if config.SoftDelete && columnType.Name() == softDeleteColumnName(config) {
+				if softDeleteType(modelColumnType) {
+					modelColumnType, importPath = "gorm.DeletedAt", "gorm.io/gorm"
+				} else {
+					report.warnf("%s.%s is not a nullable time column and cannot hold soft deletes", tableName, columnType.Name())
+				}
+			}
+		}
+		serializer := columnSerializer(config, tableName, columnType.Name())
+		switch {
+		case serializer == "unixtime" && !timeColumn:
+			report.warnf("%s.%s is not a time column, serializer:unixtime only reads Unix seconds from time columns and is not used", tableName, columnType.Name())
+			serializer = ""
+		case serializer == "unixtime":
+			if _, overridden := columnTypeOverride(config, tableName, columnType.Name()); !overridden {
+				modelColumnType, importPath = "int64", ""
+				if nullable && !primaryKey {
+					modelColumnType = "*int64"
+				}
+			}
+		case serializer == "gob" && !binaryColumn:
+			report.warnf("%s.%s is not a binary column, serializer:gob is not used", tableName, columnType.Name())
+			serializer = ""
+		}
+		netipAddr := serializer == "" && netipColumn(modelColumnType)
+		modelColumnType = aliasType(modelColumnType, importPath, aliases)
+		if importPath != "" && !strings.Contains(strings.Join(modelImports, ","), importPath) {
+			modelImports = append(modelImports, importPath)
+		}
+
+		var gormOptions []string
+		if primaryKey && autoRandomBits > 0 {
+			gormOptions = tidbAutoRandomOptions(columnType, autoRandomBits)
+		} else {
+			if primaryKey {
+				gormOptions = append(gormOptions, "primaryKey")
+			}
+			if autoIncrement {
+				gormOptions = append(gormOptions, "autoIncrement")
+			}
+		}
+		if collation, ok := collations[columnType.Name()]; ok {
+			gormOptions = append(gormOptions, collation)
+		}
+		if netipAddr {
+			gormOptions = append(gormOptions, "serializer:netip")
+		}
+		if serializer != "" {
+			gormOptions = append(gormOptions, "serializer:"+serializer)
+		}
+		if gormType != "" {
+			gormOptions = append(gormOptions, "type:"+gormType)
+		}
+		if config.DefaultTags && hasDefault && !autoIncrement {
+			options, ok := defaultOptions(defaultValue, modelColumnType, onUpdate[columnType.Name()])
+			if !ok {
+				report.warnf("Default of %s.%s cannot be written as a gorm tag: %s", tableName, columnType.Name(), defaultValue)
+			}
+			gormOptions = append(gormOptions, options...)
+		}
+		if readOnly {
+			gormOptions = append(gormOptions, "->")
+		}
+		if driver != "clickhouse" && gormType == "" {
+			if option := timePrecisionOption(driver, columnType, modelColumnType); option != "" {
+				gormOptions = append(gormOptions, option)
+			}
 		}
 
 		column := Column{
-			Name:     camelCase(columnType.Name()),
-			Type:     modelColumnType,
-			GormName: columnType.Name(),
+			Name:          camelCase(columnType.Name()),
+			Type:          modelColumnType,
+			GormName:      columnType.Name(),
+			GormOptions:   gormOptions,
+			Tags:          serializationTags(config, tableName, columnType.Name()),
+			Nullable:      nullable,
+			PrimaryKey:    primaryKey,
+			AutoIncrement: autoIncrement || (primaryKey && autoRandomBits > 0),
+			HasDefault:    hasDefault,
+			DatabaseType:  columnType.DatabaseTypeName(),
+			Ignored:       ignored,
+			Serializer:    serializer,
 			// Add other fields as necessary
 		}
+		if databaseType, ok := columnType.ColumnType(); ok {
+			column.DatabaseType = databaseType
+		}
+		if config.TypeComments {
+			column.TypeComment = typeComment(column.DatabaseType, nullable)
+		}
+		column.Tags = append(column.Tags, bindingTags(config, tableName, column)...)
+		if config.Swag {
+			column.Tags = append(column.Tags, swagTags(column)...)
+			if comment, ok := columnType.Comment(); ok {
+				column.Comment = strings.Join(strings.Fields(comment), " ")
+			}
+		}
+		applyTagRules(config.TagRules, tableName, &column)
+		sortTags(column.Tags)
 		columns = append(columns, column)
 	}
+	foreignKeyColumns := map[string]bool{}
+	for _, foreignKey := range foreignKeys {
+		if foreignKey.Table == tableName {
+			foreignKeyColumns[foreignKey.Column] = true
+		}
+	}
+	orderColumns(columns, config.FieldOrder, foreignKeyColumns, enums)
+	report.Tables++
+	report.Columns += len(columns)
+	if len(enums) > 0 {
+		for _, importPath := range []string{"database/sql/driver", "fmt"} {
+			if !strings.Contains(strings.Join(modelImports, ","), importPath) {
+				modelImports = append(modelImports, importPath)
+			}
+		}
+	}
 
-	// depluralize table name
-	depluraizedTableName := inflection.Singular(tableName)
+	columns, money, warnings := moneyFields(config, tableName, columns)
+	for _, warning := range warnings {
+		report.warnf("%s", warning)
+	}
 
-	table := Table{
-		TableName:    camelCase(depluraizedTableName),
-		Columns:      columns,
-		DBTableName:  tableName,
-		ModelImports: modelImports,
+	var checks []CheckConstraint
+	if config.Checks {
+		checks, err = loadCheckConstraints(db, driver, tableName)
+		if err != nil {
+			log.Fatalf("Failed to get check constraints for table %s: %v", tableName, err)
+		}
+		for i := range checks {
+			checks[i].Condition = checkCondition(checks[i].Clause, columns)
+			if checks[i].Condition == "" {
+				report.warnf("CHECK constraint %s on %s cannot be translated to Go: %s", checks[i].Name, tableName, checks[i].Clause)
+				continue
+			}
+			if !strings.Contains(strings.Join(modelImports, ","), "errors") {
+				modelImports = append(modelImports, "errors")
+			}
+			if strings.Contains(checks[i].Condition, "utf8.") && !strings.Contains(strings.Join(modelImports, ","), "unicode/utf8") {
+				modelImports = append(modelImports, "unicode/utf8")
+			}
+		}
 	}
 
-	tmpl, err := template.New("model").Parse(modelTemplate)
+	indexes, err := loadIndexes(db, driver, tableName)
 	if err != nil {
-		log.Fatalf("Failed to parse template: %v", err)
+		log.Fatalf("Failed to get indexes for table %s: %v", tableName, err)
+	}
+	// Upserts refer to composite unique indexes by name, and FULLTEXT and
+	// prefix indexes cannot be inferred from the fields, so AutoMigrate needs
+	// their tags to create them the same. Those that cannot be tagged are
+	// kept in the doc comment of the model.
+	var untaggedIndexes []string
+	for _, index := range indexes {
+		if taggedIndex(index) && !indexTags(columns, index) {
+			report.warnf("Index %s of %s has columns that are not in the model and is not tagged", index.Name, tableName)
+			untaggedIndexes = append(untaggedIndexes, indexDefinition(index))
+		}
+	}
+	// ClickHouse tables have no secondary indexes GORM can read
+	if config.SoftDelete && driver != "clickhouse" {
+		if column, ok := softDeleteColumn(config, Table{Columns: columns}); ok && !softDeleteIndexes(columns, indexes, column.GormName) {
+			report.warnf("%s.%s has no index, queries skipping soft-deleted rows scan the whole table", tableName, column.GormName)
+		}
+	}
+
+	var fields []StringField
+	if config.Stringer && hasColumn(columns, "string") {
+		report.warnf("%s has a String column, String() is not generated", tableName)
+	} else if config.Stringer {
+		fields = stringFields(config, tableName, columns)
+		if len(fields) > 0 && !strings.Contains(strings.Join(modelImports, ","), "fmt") {
+			modelImports = append(modelImports, "fmt")
+		}
 	}
 
-	file, err := os.Create(fmt.Sprintf("%s/%s.go", destPath, table.TableName))
+	associations := buildAssociations(tableName, columns, foreignKeys)
+	associations = append(associations, polymorphicAssociations(tableName, columns, associations, config.Polymorphic)...)
+
+	// Read-only models get no methods or hooks writing them
+	var version *Column
+	if !readOnly {
+		version = versionColumn(config, tableName, columns)
+	}
+	var versionKeys []string
+	if version != nil {
+		for _, column := range columns {
+			if column.PrimaryKey {
+				versionKeys = append(versionKeys, column.Name)
+			}
+		}
+		if len(versionKeys) == 0 {
+			report.warnf("%s has no primary key, UpdateWithVersion is not generated for %s", tableName, version.GormName)
+			version = nil
+		}
+	}
+	if version != nil {
+		if version.Comment == "" {
+			version.Comment = "Optimistic locking version, incremented by UpdateWithVersion"
+		}
+		for _, importPath := range []string{"reflect", "gorm.io/gorm", "gorm.io/gorm/clause"} {
+			if !strings.Contains(strings.Join(modelImports, ","), importPath) {
+				modelImports = append(modelImports, importPath)
+			}
+		}
+	}
+
+	var tableTimeComment string
+	if hasTimeColumns {
+		tableTimeComment = timeComment(driver, config.TimeMode)
+	}
+
+	var auditUser *AuditUser
+	if !readOnly {
+		auditUser = auditUserColumns(config, tableName, columns)
+	}
+	if auditUser != nil && auditUser.Hooks && !strings.Contains(strings.Join(modelImports, ","), "gorm.io/gorm") {
+		modelImports = append(modelImports, "gorm.io/gorm")
+	}
+
+	var cloneEqualMethods *CloneEqual
+	if config.CloneEqual && (hasColumn(columns, "clone") || hasColumn(columns, "equal")) {
+		report.warnf("%s has a Clone or Equal column, Clone() and Equal() are not generated", tableName)
+	} else if config.CloneEqual {
+		methods := buildCloneEqual(columns, money, associations)
+		for _, importPath := range methods.Imports {
+			if !strings.Contains(strings.Join(modelImports, ","), importPath) {
+				modelImports = append(modelImports, importPath)
+			}
+		}
+		cloneEqualMethods = &methods
+	}
+
+	return Table{
+		TableName:       name,
+		Columns:         columns,
+		Enums:           enums,
+		MoneyFields:     money,
+		Associations:    associations,
+		Checks:          checks,
+		Indexes:         indexes,
+		StringFields:    fields,
+		CloneEqual:      cloneEqualMethods,
+		VersionColumn:   version,
+		VersionKeys:     versionKeys,
+		AuditUser:       auditUser,
+		DBTableName:     tableName,
+		AuditOf:         auditOf,
+		TimeComment:     tableTimeComment,
+		TableNameMethod: !config.OmitTableName || schema.NamingStrategy{}.TableName(name) != tableName,
+		ReadOnly:        readOnly,
+		ShardPattern:    shardPattern,
+		UntaggedIndexes: untaggedIndexes,
+		ModelImports:    modelImports,
+		ImportAliases:   aliases,
+	}
+}
+
+// openContext opens a connection pool and connects until ctx is done. The
+// MySQL and ClickHouse dialectors read the server version without a context,
+// which then runs on the open connection. The ClickHouse driver also ignores
+// the context of the handshake, so connecting is abandoned rather than
+// cancelled.
+func openContext(ctx context.Context, driverName, dsn string) (*sql.DB, error) {
+	conn, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	connected := make(chan error, 1)
+	go func() {
+		connected <- conn.PingContext(ctx)
+	}()
+	select {
+	case err = <-connected:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
 	if err != nil {
-		log.Fatalf("Failed to create file: %v", err)
+		conn.Close()
+		return nil, err
 	}
-	defer file.Close()
+	return conn, nil
+}
+
+// writeTemplate renders a Go template to path and reports whether the file
+// was written, updated or unchanged.
+func writeTemplate(text, path string, data interface{}) string {
+	content := renderTemplate(text, data)
 
-	err = tmpl.Execute(file, table)
+	// Format like gofmt, aligning adjacent fields and their tags
+	source, err := format.Source(content)
+	if err != nil {
+		// Keep the unformatted output next to the file to find the error in,
+		// leaving the file itself, which may hold hand edits, untouched
+		if dryRun {
+			log.Fatalf("Failed to format %s: %v", path, err)
+		}
+		broken := path + ".broken"
+		if writeErr := replaceFile(broken, content, 0o644); writeErr != nil {
+			log.Fatalf("Failed to format %s: %v, and to write %s: %v", path, err, broken, writeErr)
+		}
+		log.Fatalf("Failed to format %s, the unformatted output is in %s: %v", path, broken, err)
+	}
+	return writeFile(path, source)
+}
+
+// modelPackage is the package name of the generated files, set by
+// -package. The templates are written for package models.
+var modelPackage = "models"
+
+// packageClause matches the package clause of a template, whose name is
+// replaced by modelPackage
+var packageClause = regexp.MustCompile(`(?m)^package (models)(_test)?$`)
+
+// renderTemplate executes a template after the generated header.
+func renderTemplate(text string, data interface{}) []byte {
+	tmpl, err := template.New("model").Parse(text)
+	if err != nil {
+		log.Fatalf("Failed to parse template: %v", err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(generatedHeader())
+	err = tmpl.Execute(&buf, data)
 	if err != nil {
 		log.Fatalf("Failed to execute template: %v", err)
 	}
+	content := buf.Bytes()
+	if modelPackage != "models" {
+		if clause := packageClause.FindSubmatchIndex(content); clause != nil {
+			content = append(append(append([]byte{}, content[:clause[2]]...), modelPackage...), content[clause[3]:]...)
+		}
+	}
+	return content
+}
+
+// writeFile writes content to path and reports whether the file was written,
+// updated or unchanged. Unchanged files are not rewritten, keeping their
+// modification time so builds are not triggered needlessly. Other files are
+// written to a temporary file renamed into place, so a crash never leaves a
+// half-written file behind, after backing up updated files to backupDir.
+// Files edited since the previous run are merged with content instead, and
+// reported as merged or conflicted.
+func writeFile(path string, content []byte) string {
+	status := fileWritten
+	mode := os.FileMode(0o644)
+	recordGenerated(path, content)
+	existing, err := os.ReadFile(path)
+	if err == nil {
+		if bytes.Equal(existing, content) {
+			return fileUnchanged
+		}
+		status = fileUpdated
+		if merged, conflicted, ok := mergeEdited(path, existing, content); ok {
+			if bytes.Equal(existing, merged) {
+				return fileUnchanged
+			}
+			content, status = merged, fileMerged
+			if conflicted {
+				status = fileConflicted
+			}
+		}
+	}
+	if dryRun {
+		printDiff(path, existing, content)
+		return status
+	}
+	if status != fileWritten {
+		if info, err := os.Stat(path); err == nil {
+			mode = info.Mode().Perm()
+		}
+		if backupDir != "" {
+			if err := backupFile(path, existing, mode); err != nil {
+				log.Fatalf("Failed to back up file %s: %v", path, err)
+			}
+		}
+	}
+
+	if err := replaceFile(path, content, mode); err != nil {
+		log.Fatalf("Failed to write file %s: %v", path, err)
+	}
+	return status
+}
+
+// replaceFile writes content to a temporary file next to path, as a rename
+// is only atomic within a file system, and renames it to path.
+func replaceFile(path string, content []byte, mode os.FileMode) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(content)
+	if err == nil {
+		err = file.Chmod(mode)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+	}
+	return err
+}
+
+// mysqlColumnType maps a MySQL column type to a Go type and the import it requires, if any.
+// ok is false when an unknown type falls back to string.
+func mysqlColumnType(databaseType string) (string, string, bool) {
+	switch databaseType {
+	case "datetime", "timestamp", "date", "time":
+		return "time.Time", "time", true
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
+		return "int", "", true
+	case "float", "double", "real":
+		return "float64", "", true
+	case "decimal", "numeric":
+		return "string", "", true // or use a custom decimal type
+	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext":
+		return "string", "", true
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
+		return "[]byte", "", true
+	case "bit":
+		return "[]uint8", "", true
+	case "bool", "boolean":
+		return "bool", "", true
+	case "json":
+		return "json.RawMessage", "encoding/json", true
+	case "enum", "set":
+		return "string", "", true
+	case "uuid":
+		// MariaDB 10.7+
+		return "uuid.UUID", "github.com/google/uuid", true
+	case "inet4", "inet6":
+		// MariaDB 10.10+ and 10.5+
+		return "netip.Addr", "net/netip", true
+	default:
+		return "string", "", false // default to string for any other types
+	}
+}
+
+// modelName returns the struct name for a table, e.g. user_roles -> UserRole.
+func modelName(tableName string) string {
+	// depluralize table name
+	return camelCase(inflection.Singular(tableName))
+}
+
+// lowerFirst lowercases the first letter of a name, e.g. for unexported
+// identifiers derived from a model name.
+func lowerFirst(s string) string {
+	first, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(first)) + s[size:]
 }
 
 func camelCase(s string) string {
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// mergeStoreName is the directory in the destination keeping the content
+// generated for the files of the lock file, keyed by its hash, which files
+// edited by hand are merged against
+const mergeStoreName = ".generate-gorm-models"
+
+var (
+	// mergeStore is the directory of generated content of the destination,
+	// or "" when files are not merged, e.g. by the commands
+	mergeStore string
+	// mergeBases maps the files of the previous run to the hash of the
+	// content generated for them
+	mergeBases = map[string]string{}
+	// generatedHashes maps the files of this run to the hash of the content
+	// generated for them, which the lock file records even when edits were
+	// merged into it
+	generatedHashes = map[string]string{}
+)
+
+// recordGenerated records the content generated for a file, and keeps it in
+// the merge store as the base of the next merge.
+func recordGenerated(path string, content []byte) {
+	if filepath.Base(path) == lockFileName {
+		return
+	}
+	hash := hashString(content)
+	generatedHashes[filepath.Clean(path)] = hash
+	if mergeStore == "" || dryRun {
+		return
+	}
+	stored := filepath.Join(mergeStore, hash)
+	if _, err := os.Stat(stored); err == nil {
+		return
+	}
+	if err := os.MkdirAll(mergeStore, 0o755); err != nil {
+		log.Fatalf("Failed to create %s: %v", mergeStore, err)
+	}
+	if err := replaceFile(stored, content, 0o644); err != nil {
+		log.Fatalf("Failed to write %s: %v", stored, err)
+	}
+}
+
+// mergeEdited merges the changes between the content previously generated
+// for a file and the newly generated content into the file, when it was
+// edited since. ok is false when it was not edited, or the previous run did
+// not record it. Without the previous content, the edited and generated
+// files conflict as a whole.
+func mergeEdited(path string, existing, generated []byte) (merged []byte, conflicted, ok bool) {
+	hash, ok := mergeBases[filepath.Clean(path)]
+	if mergeStore == "" || !ok || hashString(existing) == hash {
+		return nil, false, false
+	}
+	base, err := os.ReadFile(filepath.Join(mergeStore, hash))
+	if err != nil {
+		base = nil
+	}
+	merged, conflicted = merge3(base, existing, generated)
+	return merged, conflicted, true
+}
+
+// pruneMergeStore removes the content of the merge store that no file of the
+// lock refers to.
+func pruneMergeStore(lock Lock) {
+	if mergeStore == "" || dryRun {
+		return
+	}
+	used := map[string]bool{}
+	for _, hash := range lock.files() {
+		used[hash] = true
+	}
+	entries, err := os.ReadDir(mergeStore)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !used[entry.Name()] {
+			if err := os.Remove(filepath.Join(mergeStore, entry.Name())); err != nil {
+				log.Fatalf("Failed to remove %s: %v", filepath.Join(mergeStore, entry.Name()), err)
+			}
+		}
+	}
+}
+
+// mergeChange is a change of an edit script, replacing the base lines
+// [baseStart, baseEnd) by lines.
+type mergeChange struct {
+	side               int
+	baseStart, baseEnd int
+	lines              []string
+}
+
+// merge3 merges the changes from base to edited and from base to generated
+// line by line, like diff3. Changes of the same or adjacent lines that
+// differ are written as a conflict between markers, the edited lines first.
+// conflicted reports whether there are any.
+func merge3(base, edited, generated []byte) ([]byte, bool) {
+	baseLines := splitLines(base)
+	changes := append(mergeChanges(baseLines, splitLines(edited), 0), mergeChanges(baseLines, splitLines(generated), 1)...)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].baseStart < changes[j].baseStart
+	})
+
+	var merged []string
+	conflicted := false
+	position := 0
+	for i := 0; i < len(changes); {
+		// Group the changes touching each other, and rebuild the lines of
+		// each side for the base lines they cover
+		start, end := changes[i].baseStart, changes[i].baseEnd
+		j := i + 1
+		for ; j < len(changes) && changes[j].baseStart <= end; j++ {
+			end = max(end, changes[j].baseEnd)
+		}
+		var sides [2][]string
+		changed := [2]bool{}
+		for side := range sides {
+			at := start
+			for _, change := range changes[i:j] {
+				if change.side != side {
+					continue
+				}
+				sides[side] = append(sides[side], baseLines[at:change.baseStart]...)
+				sides[side] = append(sides[side], change.lines...)
+				at = change.baseEnd
+				changed[side] = true
+			}
+			sides[side] = append(sides[side], baseLines[at:end]...)
+		}
+
+		merged = append(merged, baseLines[position:start]...)
+		switch {
+		case !changed[1] || slices.Equal(sides[0], sides[1]):
+			merged = append(merged, sides[0]...)
+		case !changed[0]:
+			merged = append(merged, sides[1]...)
+		default:
+			conflicted = true
+			merged = append(merged, "<<<<<<< edited")
+			merged = append(merged, sides[0]...)
+			merged = append(merged, "=======")
+			merged = append(merged, sides[1]...)
+			merged = append(merged, ">>>>>>> generated")
+		}
+		position = end
+		i = j
+	}
+	merged = append(merged, baseLines[position:]...)
+	if len(merged) == 0 {
+		return nil, conflicted
+	}
+	return []byte(strings.Join(merged, "\n") + "\n"), conflicted
+}
+
+// mergeChanges returns the changes of the edit script from base to lines,
+// each run of removed and added lines being one.
+func mergeChanges(base, lines []string, side int) []mergeChange {
+	var changes []mergeChange
+	at := 0
+	script := diffLines(base, lines)
+	for i := 0; i < len(script); {
+		if script[i].Kind == ' ' {
+			at++
+			i++
+			continue
+		}
+		change := mergeChange{side: side, baseStart: at, baseEnd: at}
+		for ; i < len(script) && script[i].Kind != ' '; i++ {
+			if script[i].Kind == '-' {
+				change.baseEnd++
+			} else {
+				change.lines = append(change.lines, script[i].Text)
+			}
+		}
+		at = change.baseEnd
+		changes = append(changes, change)
+	}
+	return changes
+}
@@ -0,0 +1,86 @@
+package main
+
+import "testing"
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name                    string
+		base, edited, generated string
+		want                    string
+		conflicted              bool
+	}{
+		{
+			name:      "unchanged",
+			base:      "a\nb\nc\n",
+			edited:    "a\nb\nc\n",
+			generated: "a\nb\nc\n",
+			want:      "a\nb\nc\n",
+		},
+		{
+			name:      "edited only",
+			base:      "a\nb\nc\n",
+			edited:    "a\nB\nc\n",
+			generated: "a\nb\nc\n",
+			want:      "a\nB\nc\n",
+		},
+		{
+			name:      "generated only",
+			base:      "a\nb\nc\n",
+			edited:    "a\nb\nc\n",
+			generated: "a\nb\nc\nd\n",
+			want:      "a\nb\nc\nd\n",
+		},
+		{
+			name:      "separate changes",
+			base:      "a\nb\nc\nd\ne\n",
+			edited:    "A\nb\nc\nd\ne\n",
+			generated: "a\nb\nc\nd\nE\n",
+			want:      "A\nb\nc\nd\nE\n",
+		},
+		{
+			name:      "same change",
+			base:      "a\nb\nc\n",
+			edited:    "a\nB\nc\n",
+			generated: "a\nB\nc\n",
+			want:      "a\nB\nc\n",
+		},
+		{
+			name:       "conflicting changes",
+			base:       "a\nb\nc\n",
+			edited:     "a\nedited\nc\n",
+			generated:  "a\ngenerated\nc\n",
+			want:       "a\n<<<<<<< edited\nedited\n=======\ngenerated\n>>>>>>> generated\nc\n",
+			conflicted: true,
+		},
+		{
+			name:       "adjacent changes",
+			base:       "a\nb\nc\n",
+			edited:     "a\nB\nc\n",
+			generated:  "a\nb\nC\n",
+			want:       "a\n<<<<<<< edited\nB\nc\n=======\nb\nC\n>>>>>>> generated\n",
+			conflicted: true,
+		},
+		{
+			name:       "no base",
+			edited:     "a\n",
+			generated:  "b\n",
+			want:       "<<<<<<< edited\na\n=======\nb\n>>>>>>> generated\n",
+			conflicted: true,
+		},
+		{
+			name:      "removed by both",
+			base:      "a\nb\n",
+			edited:    "a\n",
+			generated: "a\n",
+			want:      "a\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged, conflicted := merge3([]byte(test.base), []byte(test.edited), []byte(test.generated))
+			if string(merged) != test.want || conflicted != test.conflicted {
+				t.Errorf("merge3() = %q, %v, want %q, %v", merged, conflicted, test.want, test.conflicted)
+			}
+		})
+	}
+}
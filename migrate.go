@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+var migrateTemplate = `package models
+
+import "gorm.io/gorm"
+
+// AllModels returns a model of every generated table, with the tables
+// referenced by foreign keys before the tables referencing them.
+func AllModels() []interface{} {
+    return []interface{}{
+{{- range .}}
+        &{{.}}{},
+{{- end}}
+    }
+}
+
+// AutoMigrateAll migrates the tables of all models, parents first.
+func AutoMigrateAll(db *gorm.DB) error {
+    return db.AutoMigrate(AllModels()...)
+}
+`
+
+// orderModels returns the model names of tables with every table after the
+// tables it references, keeping the given order otherwise. Self references
+// are ignored; other cycles cannot be ordered and are returned as an error.
+func orderModels(tables []Table, foreignKeys []ForeignKey) ([]string, error) {
+	models := map[string]string{}
+	for _, table := range tables {
+		models[table.DBTableName] = table.TableName
+	}
+	parents := map[string][]string{}
+	for _, fk := range foreignKeys {
+		if fk.Table == fk.ReferencedTable || models[fk.Table] == "" || models[fk.ReferencedTable] == "" {
+			continue
+		}
+		if !containsString(parents[fk.Table], fk.ReferencedTable) {
+			parents[fk.Table] = append(parents[fk.Table], fk.ReferencedTable)
+		}
+	}
+
+	var ordered []string
+	done := map[string]bool{}
+	var path []string
+	var visit func(tableName string) error
+	visit = func(tableName string) error {
+		if done[tableName] {
+			return nil
+		}
+		for i, visiting := range path {
+			if visiting == tableName {
+				return fmt.Errorf("foreign keys form a cycle: %s", strings.Join(append(path[i:], tableName), " -> "))
+			}
+		}
+		path = append(path, tableName)
+		for _, parent := range parents[tableName] {
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		done[tableName] = true
+		ordered = append(ordered, models[tableName])
+		return nil
+	}
+	for _, table := range tables {
+		if err := visit(table.DBTableName); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+var gormigrateTemplate = `package models
+
+import (
+    "github.com/go-gormigrate/gormigrate/v2"
+    "gorm.io/gorm"
+)
+
+// Migrations returns a gormigrate migration per generated table, with the
+// tables referenced by foreign keys first, e.g.
+// gormigrate.New(db, gormigrate.DefaultOptions, models.Migrations()).Migrate().
+// They migrate the current models, so they also apply to an existing
+// database. Migrations added later should declare the structs they migrate,
+// as the models change with the schema.
+func Migrations() []*gormigrate.Migration {
+    return []*gormigrate.Migration{
+{{- range .}}
+        {
+            ID: "{{.ID}}",
+            Migrate: func(tx *gorm.DB) error {
+                return tx.AutoMigrate(&{{.Model}}{})
+            },
+            Rollback: func(tx *gorm.DB) error {
+                return tx.Migrator().DropTable(&{{.Model}}{})
+            },
+        },
+{{- end}}
+    }
+}
+`
+
+// GormigrateMigration is a migration of the generated gormigrate.go file.
+type GormigrateMigration struct {
+	ID    string
+	Model string
+}
+
+// buildGormigrateMigrations derives a migration per model, in the order of
+// orderModels, with the IDs of the migrations command, e.g.
+// 000001_create_users.
+func buildGormigrateMigrations(tables []Table, models []string) []GormigrateMigration {
+	tableNames := map[string]string{}
+	for _, table := range tables {
+		tableNames[table.TableName] = table.DBTableName
+	}
+	var migrations []GormigrateMigration
+	for i, model := range models {
+		migrations = append(migrations, GormigrateMigration{
+			ID:    fmt.Sprintf("%06d_create_%s", i+1, tableNames[model]),
+			Model: model,
+		})
+	}
+	return migrations
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// autoIncrementOption is the AUTO_INCREMENT table option of MySQL's SHOW
+// CREATE TABLE, which holds the next id of the existing table
+var autoIncrementOption = regexp.MustCompile(` AUTO_INCREMENT=\d+`)
+
+// createView matches the statements creating a view, e.g. CREATE
+// ALGORITHM=UNDEFINED DEFINER=`root`@`%` SQL SECURITY DEFINER VIEW on MySQL
+// or CREATE MATERIALIZED VIEW on ClickHouse
+var createView = regexp.MustCompile(`(?i)^CREATE\s+((OR\s+REPLACE|ALGORITHM=\S+|DEFINER=\S+|SQL\s+SECURITY\s+\S+|MATERIALIZED|LIVE|WINDOW)\s+)*VIEW\b`)
+
+const postgresColumnsQuery = `SELECT a.attname, format_type(a.atttypid, a.atttypmod), a.attnotnull, COALESCE(pg_get_expr(d.adbin, d.adrelid), ''), a.attidentity::text, a.attgenerated::text
+FROM pg_attribute a
+LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+WHERE a.attrelid = ?::regclass AND a.attnum > 0 AND NOT a.attisdropped
+ORDER BY a.attnum`
+
+const postgresConstraintsQuery = `SELECT conname, pg_get_constraintdef(oid)
+FROM pg_constraint
+WHERE conrelid = ?::regclass AND contype IN ('p', 'u', 'f', 'c', 'x')
+ORDER BY CASE contype WHEN 'p' THEN 0 WHEN 'u' THEN 1 WHEN 'c' THEN 2 ELSE 3 END, conname`
+
+// postgresIndexesQuery selects the indexes not created by a primary key,
+// unique or exclusion constraint, which are part of the constraints
+const postgresIndexesQuery = `SELECT pg_get_indexdef(i.indexrelid)
+FROM pg_index i
+WHERE i.indrelid = ?::regclass AND NOT EXISTS (
+    SELECT 1 FROM pg_constraint c WHERE c.conrelid = i.indrelid AND c.conindid = i.indexrelid AND c.contype IN ('p', 'u', 'x')
+)
+ORDER BY i.indexrelid`
+
+// postgresSerialTypes are the serial types of the integer columns whose
+// default is the next value of their sequence
+var postgresSerialTypes = map[string]string{
+	"smallint": "smallserial",
+	"integer":  "serial",
+	"bigint":   "bigserial",
+}
+
+// Migration is a golang-migrate migration, written as
+// <version>_<name>.up.sql and <version>_<name>.down.sql.
+type Migration struct {
+	Name string
+	// Table is the table the migration creates, or "" for the enum types
+	Table string
+	Up    string
+	Down  string
+}
+
+// migrations writes golang-migrate migrations creating the given tables to
+// dir, one per table numbered in foreign key order, so applying them creates
+// every table after the tables it references and rolling them back drops it
+// first.
+func migrations(db *gorm.DB, driver string, tableNames []string, dir string, report *Report) {
+	foreignKeys, err := loadForeignKeys(db, driver)
+	if err != nil {
+		log.Fatalf("Failed to get foreign keys: %s", explain(err))
+	}
+	var tables []Table
+	for _, tableName := range tableNames {
+		tables = append(tables, Table{TableName: tableName, DBTableName: tableName})
+	}
+	order, err := orderModels(tables, foreignKeys)
+	if err != nil {
+		log.Fatalf("Failed to order tables to migrate: %v", err)
+	}
+
+	var all []Migration
+	if driver == "postgres" || driver == "cockroach" {
+		if types, ok := postgresEnumMigration(db, order); ok {
+			all = append(all, types)
+		}
+	}
+	// Views select from the tables, so they are created after all of them
+	var views []Migration
+	for _, tableName := range order {
+		up, err := createTableStatement(db, driver, tableName)
+		if err != nil {
+			log.Fatalf("Failed to get the definition of table %s: %s", tableName, explainTable(db, tableName, err))
+		}
+		drop := "DROP TABLE"
+		switch {
+		case !createView.MatchString(up):
+		case driver != "clickhouse" && strings.Contains(strings.ToUpper(createView.FindString(up)), "MATERIALIZED"):
+			// ClickHouse drops materialized views with DROP VIEW too
+			drop = "DROP MATERIALIZED VIEW"
+		default:
+			drop = "DROP VIEW"
+		}
+		migration := Migration{
+			Name:  "create_" + tableName,
+			Table: tableName,
+			Up:    up,
+			Down:  fmt.Sprintf("%s IF EXISTS %s;", drop, db.Statement.Quote(tableName)),
+		}
+		if drop == "DROP TABLE" {
+			all = append(all, migration)
+		} else {
+			views = append(views, migration)
+		}
+	}
+	all = append(all, views...)
+
+	if err := mkdirAll(dir); err != nil {
+		log.Fatalf("Failed to create migrations directory: %v", err)
+	}
+	for i, migration := range all {
+		// The sequential versions of migrate create -seq
+		prefix := fmt.Sprintf("%s/%06d_%s", dir, i+1, migration.Name)
+		report.addTableFile(migration.Table, prefix+".up.sql", writeFile(prefix+".up.sql", []byte(migration.Up+"\n")))
+		report.addTableFile(migration.Table, prefix+".down.sql", writeFile(prefix+".down.sql", []byte(migration.Down+"\n")))
+	}
+}
+
+// createTableStatement returns the CREATE TABLE statement of a table, with
+// its indexes and constraints, as the database reports it. Postgres has no
+// SHOW CREATE TABLE, so its statement is built from the catalog.
+func createTableStatement(db *gorm.DB, driver, tableName string) (string, error) {
+	if driver == "postgres" {
+		return postgresCreateTable(db, tableName)
+	}
+
+	rows, err := db.Raw("SHOW CREATE TABLE " + db.Statement.Quote(tableName)).Rows()
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	names, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("table %s does not exist", tableName)
+	}
+	values := make([]sql.NullString, len(names))
+	targets := make([]interface{}, len(names))
+	for i := range values {
+		targets[i] = &values[i]
+	}
+	if err := rows.Scan(targets...); err != nil {
+		return "", err
+	}
+
+	// MySQL and TiDB name the statement column Create Table, or Create View
+	// for views, CockroachDB create_statement and ClickHouse statement
+	var statement string
+	for i, name := range names {
+		switch name {
+		case "Create Table", "Create View", "create_statement", "statement":
+			statement = values[i].String
+		}
+	}
+	if statement == "" {
+		return "", fmt.Errorf("%s is not a table", tableName)
+	}
+	switch driver {
+	case "mysql":
+		statement = autoIncrementOption.ReplaceAllString(statement, "")
+	case "tidb":
+		statement = tidbCreateTable(autoIncrementOption.ReplaceAllString(statement, ""))
+	case "clickhouse":
+		// ClickHouse qualifies the table with the database, which the
+		// migrations may be applied to under another name
+		statement = strings.Replace(statement, db.Migrator().CurrentDatabase()+".", "", 1)
+	}
+	return strings.TrimSuffix(statement, ";") + ";", nil
+}
+
+// postgresViewQuery selects the kind of a relation, and its query when it is
+// a view or materialized view
+const postgresViewQuery = `SELECT c.relkind::text, CASE WHEN c.relkind IN ('v', 'm') THEN pg_get_viewdef(c.oid) ELSE '' END
+FROM pg_class c
+WHERE c.oid = ?::regclass`
+
+// postgresCreateTable builds the CREATE TABLE statement of a Postgres table
+// from its columns and constraints, followed by its other indexes. Columns
+// defaulting to their sequence become serial columns, which create it. Views
+// get a CREATE VIEW statement of their query instead.
+func postgresCreateTable(db *gorm.DB, tableName string) (string, error) {
+	quoted := db.Statement.Quote(tableName)
+	var kind, query string
+	if err := db.Raw(postgresViewQuery, quoted).Row().Scan(&kind, &query); err != nil {
+		return "", err
+	}
+	switch kind {
+	case "v":
+		return "CREATE VIEW " + quoted + " AS\n" + strings.TrimSuffix(strings.TrimSpace(query), ";") + ";", nil
+	case "m":
+		statement := "CREATE MATERIALIZED VIEW " + quoted + " AS\n" + strings.TrimSuffix(strings.TrimSpace(query), ";") + ";"
+		return postgresWithIndexes(db, quoted, statement)
+	}
+
+	var definitions []string
+	rows, err := db.Raw(postgresColumnsQuery, quoted).Rows()
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, dataType, defaultValue, identity, generated string
+		var notNull bool
+		if err := rows.Scan(&name, &dataType, &notNull, &defaultValue, &identity, &generated); err != nil {
+			return "", err
+		}
+		definition := db.Statement.Quote(name) + " "
+		if serial, ok := postgresSerialTypes[dataType]; ok && strings.HasPrefix(defaultValue, "nextval(") {
+			definition += serial
+			defaultValue = ""
+		} else {
+			definition += dataType
+		}
+		if notNull {
+			definition += " NOT NULL"
+		}
+		switch {
+		case generated == "s":
+			definition += " GENERATED ALWAYS AS (" + defaultValue + ") STORED"
+		case identity == "a":
+			definition += " GENERATED ALWAYS AS IDENTITY"
+		case identity == "d":
+			definition += " GENERATED BY DEFAULT AS IDENTITY"
+		case defaultValue != "":
+			definition += " DEFAULT " + defaultValue
+		}
+		definitions = append(definitions, definition)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	if len(definitions) == 0 {
+		return "", fmt.Errorf("table %s has no columns", tableName)
+	}
+
+	constraints, err := db.Raw(postgresConstraintsQuery, quoted).Rows()
+	if err != nil {
+		return "", err
+	}
+	defer constraints.Close()
+	for constraints.Next() {
+		var name, definition string
+		if err := constraints.Scan(&name, &definition); err != nil {
+			return "", err
+		}
+		definitions = append(definitions, "CONSTRAINT "+db.Statement.Quote(name)+" "+definition)
+	}
+	if err := constraints.Err(); err != nil {
+		return "", err
+	}
+
+	statement := "CREATE TABLE " + quoted + " (\n    " + strings.Join(definitions, ",\n    ") + "\n);"
+	return postgresWithIndexes(db, quoted, statement)
+}
+
+// postgresWithIndexes appends the statements creating the indexes of a
+// relation not created by its constraints to statement.
+func postgresWithIndexes(db *gorm.DB, quoted, statement string) (string, error) {
+	var indexes []string
+	if err := db.Raw(postgresIndexesQuery, quoted).Scan(&indexes).Error; err != nil {
+		return "", err
+	}
+	for _, index := range indexes {
+		statement += "\n" + index + ";"
+	}
+	return statement, nil
+}
+
+// postgresEnumMigration returns the migration creating the enum types the
+// columns of the tables use, which must exist before the tables. ok is false
+// when they use none.
+func postgresEnumMigration(db *gorm.DB, tableNames []string) (Migration, bool) {
+	enums, err := loadPostgresEnums(db)
+	if err != nil {
+		log.Fatalf("Failed to get enum types: %v", err)
+	}
+	var used []string
+	for _, tableName := range tableNames {
+		var types []string
+		if err := db.Raw(`SELECT format_type(atttypid, NULL) FROM pg_attribute WHERE attrelid = ?::regclass AND attnum > 0 AND NOT attisdropped`, db.Statement.Quote(tableName)).Scan(&types).Error; err != nil {
+			log.Fatalf("Failed to get the columns of table %s: %v", tableName, err)
+		}
+		for _, typeName := range types {
+			typeName = strings.Trim(strings.TrimSuffix(typeName, "[]"), `"`)
+			if _, ok := enums[typeName]; ok && !containsString(used, typeName) {
+				used = append(used, typeName)
+			}
+		}
+	}
+	if len(used) == 0 {
+		return Migration{}, false
+	}
+
+	var up, down []string
+	for _, typeName := range used {
+		var values []string
+		for _, value := range enums[typeName] {
+			values = append(values, "'"+strings.ReplaceAll(value, "'", "''")+"'")
+		}
+		up = append(up, fmt.Sprintf("CREATE TYPE %s AS ENUM (%s);", db.Statement.Quote(typeName), strings.Join(values, ", ")))
+		down = append(down, fmt.Sprintf("DROP TYPE IF EXISTS %s;", db.Statement.Quote(typeName)))
+	}
+	return Migration{Name: "create_enum_types", Up: strings.Join(up, "\n"), Down: strings.Join(down, "\n")}, true
+}
@@ -0,0 +1,98 @@
+package main
+
+var modelTestsTemplate = `package models
+
+import (
+    "os"
+    "sync"
+    "testing"
+
+    "{{.DriverImport}}"
+    "gorm.io/gorm"
+    "gorm.io/gorm/schema"
+)
+{{- range .Tables }}
+{{- if .TableNameMethod }}
+
+var _ schema.Tabler = {{.TableName}}{}
+{{- end }}
+{{- end }}
+
+// schemaSnapshot lists the columns of every table when the models were generated.
+var schemaSnapshot = map[string][]string{
+{{- range .Tables }}
+    "{{.DBTableName}}": { {{- range $i, $column := .DBColumns}}{{if $i}}, {{end}}"{{$column}}"{{end -}} },
+{{- end }}
+}
+
+func generatedModels() map[string]interface{} {
+    return map[string]interface{}{
+{{- range .Tables }}
+        "{{.TableName}}": &{{.TableName}}{},
+{{- end }}
+    }
+}
+
+func TestModelsMatchSchemaSnapshot(t *testing.T) {
+    for name, model := range generatedModels() {
+        parsed, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+        if err != nil {
+            t.Errorf("%s: %v", name, err)
+            continue
+        }
+        columns, ok := schemaSnapshot[parsed.Table]
+        if !ok {
+            t.Errorf("%s: table %s is not in the schema snapshot", name, parsed.Table)
+            continue
+        }
+        if len(parsed.DBNames) != len(columns) {
+            t.Errorf("%s: got columns %v, want %v", name, parsed.DBNames, columns)
+            continue
+        }
+        for i, column := range columns {
+            if parsed.DBNames[i] != column {
+                t.Errorf("%s: got column %s at position %d, want %s", name, parsed.DBNames[i], i, column)
+            }
+        }
+    }
+}
+
+// TestModelsScanOneRow selects a row of every table and scans it into its
+// model. It only runs when MODELS_TEST_DSN points at the database.
+func TestModelsScanOneRow(t *testing.T) {
+    dsn := os.Getenv("MODELS_TEST_DSN")
+    if dsn == "" {
+        t.Skip("MODELS_TEST_DSN is not set")
+    }
+    db, err := gorm.Open({{.Dialector}}(dsn), &gorm.Config{})
+    if err != nil {
+        t.Fatalf("Failed to connect to database: %v", err)
+    }
+    for name, model := range generatedModels() {
+        if err := db.Limit(1).Find(model).Error; err != nil {
+            t.Errorf("%s: %v", name, err)
+        }
+    }
+}
+`
+
+// ModelTests is the data of the generated models_gen_test.go file.
+type ModelTests struct {
+	DriverImport string
+	Dialector    string
+	Tables       []Table
+}
+
+// buildModelTests returns the generated tests of the models of a driver.
+func buildModelTests(driver string, tables []Table) ModelTests {
+	tests := ModelTests{Tables: tables}
+	switch driver {
+	case "postgres", "cockroach":
+		tests.DriverImport, tests.Dialector = "gorm.io/driver/postgres", "postgres.Open"
+	case "clickhouse":
+		tests.DriverImport, tests.Dialector = "gorm.io/driver/clickhouse", "clickhouse.Open"
+	default:
+		tests.DriverImport, tests.Dialector = "gorm.io/driver/mysql", "mysql.Open"
+	}
+	return tests
+}
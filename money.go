@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+var moneyTemplate = `package models
+
+import "fmt"
+
+// Money is an amount with its currency code, stored in a pair of
+// <prefix>amount and <prefix>currency columns. Amount holds the decimal
+// value as a string, so it is never rounded.
+type Money struct {
+    Amount   string ` + "`gorm:\"column:amount\" json:\"amount\"`" + `
+    Currency string ` + "`gorm:\"column:currency\" json:\"currency\"`" + `
+}
+
+// String returns the amount followed by its currency, e.g. "19.99 EUR".
+func (m Money) String() string {
+    return fmt.Sprintf("%s %s", m.Amount, m.Currency)
+}
+`
+
+// MoneyConfig declares a pair of amount and currency columns stored as Money,
+// on every table having both or on Table only.
+type MoneyConfig struct {
+	Table    string `json:"table"`
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MoneyField is a Money field embedding an amount and currency column pair.
+type MoneyField struct {
+	Name   string
+	Prefix string
+	// Columns are the amount and currency columns
+	Columns []Column
+}
+
+// moneyPrefix returns the column prefix shared by a money column pair. GORM
+// embeds a struct by prefixing its column names, so the pair must be named
+// <prefix>amount and <prefix>currency.
+func moneyPrefix(money MoneyConfig) (string, bool) {
+	prefix := strings.TrimSuffix(money.Amount, "amount")
+	if prefix == money.Amount || money.Currency != prefix+"currency" {
+		return "", false
+	}
+	return prefix, true
+}
+
+// moneyFields replaces the configured amount and currency column pairs of a
+// table with Money fields. Nullable pairs are kept as separate columns, since
+// Money cannot hold NULL, and are returned as warnings.
+func moneyFields(config Config, tableName string, columns []Column) ([]Column, []MoneyField, []string) {
+	var fields []MoneyField
+	var warnings []string
+	for _, money := range config.Money {
+		if money.Table != "" && money.Table != tableName {
+			continue
+		}
+		amount, currency := -1, -1
+		for i, column := range columns {
+			switch column.GormName {
+			case money.Amount:
+				amount = i
+			case money.Currency:
+				currency = i
+			}
+		}
+		if amount < 0 || currency < 0 {
+			continue
+		}
+		if columns[amount].Nullable || columns[currency].Nullable {
+			warnings = append(warnings, fmt.Sprintf("%s.%s and %s.%s are nullable and are not generated as Money", tableName, money.Amount, tableName, money.Currency))
+			continue
+		}
+
+		prefix, _ := moneyPrefix(money)
+		name := camelCase(strings.TrimSuffix(prefix, "_"))
+		if name == "" {
+			name = "Money"
+		}
+		fields = append(fields, MoneyField{Name: name, Prefix: prefix, Columns: []Column{columns[amount], columns[currency]}})
+
+		var kept []Column
+		for i, column := range columns {
+			if i != amount && i != currency {
+				kept = append(kept, column)
+			}
+		}
+		columns = kept
+	}
+	return columns, fields, warnings
+}
+
+// DBColumns returns the column names of a model in field order, including the
+// columns embedded by its Money fields.
+func (table Table) DBColumns() []string {
+	var names []string
+	for _, column := range table.Columns {
+		if !column.Ignored {
+			names = append(names, column.GormName)
+		}
+	}
+	for _, money := range table.MoneyFields {
+		names = append(names, money.Prefix+"amount", money.Prefix+"currency")
+	}
+	return names
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// optionFileKeys maps the keys of the [client] group of a MySQL option file
+// to the flags they set.
+var optionFileKeys = map[string]string{
+	"user":     "dbuser",
+	"password": "dbpassword",
+	"host":     "dbhost",
+	"port":     "dbport",
+	"database": "dbname",
+}
+
+// defaultOptionFile returns ~/.my.cnf, which MySQL clients read by default.
+func defaultOptionFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".my.cnf")
+}
+
+// readOptionFile returns the options of the [client] group of a MySQL
+// option file, keyed by the flags they set, e.g. dbuser for user. Keys may
+// be written with dashes or underscores, and values may be quoted.
+// Directives such as !include are ignored.
+func readOptionFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	options := map[string]string{}
+	group := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';' || line[0] == '!':
+			continue
+		case line[0] == '[' && strings.HasSuffix(line, "]"):
+			group = strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			continue
+		}
+		if group != "client" {
+			continue
+		}
+		key, value, _ := strings.Cut(line, "=")
+		key = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(key)), "_", "-")
+		if name, ok := optionFileKeys[key]; ok {
+			options[name] = optionValue(strings.TrimSpace(value))
+		}
+	}
+	return options, scanner.Err()
+}
+
+// optionValue unquotes a value of an option file, resolving its escape
+// sequences, or strips a trailing comment from an unquoted one.
+func optionValue(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+		var unquoted strings.Builder
+		for i := 1; i < len(value); i++ {
+			switch c := value[i]; {
+			case c == '\\' && i+1 < len(value):
+				i++
+				if escaped, ok := optionEscapes[value[i]]; ok {
+					unquoted.WriteByte(escaped)
+				} else {
+					unquoted.WriteByte(value[i])
+				}
+			case c == value[0]:
+				return unquoted.String()
+			default:
+				unquoted.WriteByte(c)
+			}
+		}
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value
+}
+
+// optionEscapes are the escape sequences of option file values besides
+// escaped characters, such as \\ for a backslash
+var optionEscapes = map[byte]byte{'b': '\b', 't': '\t', 'n': '\n', 'r': '\r', 's': ' '}
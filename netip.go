@@ -0,0 +1,84 @@
+package main
+
+import "strings"
+
+var netipTemplate = `package models
+
+import (
+    "context"
+    "fmt"
+    "net/netip"
+    "reflect"
+
+    "gorm.io/gorm/schema"
+)
+
+func init() {
+    schema.RegisterSerializer("netip", NetIPSerializer{})
+}
+
+// NetIPSerializer reads and writes netip.Addr fields as their text form,
+// e.g. for MariaDB's inet4 and inet6 columns. Invalid addresses are written
+// as NULL.
+type NetIPSerializer struct{}
+
+// Scan parses the address read from the database into the field.
+func (NetIPSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+    fieldValue := reflect.New(field.FieldType).Elem()
+    if dbValue != nil {
+        var text string
+        switch v := dbValue.(type) {
+        case []byte:
+            text = string(v)
+        case string:
+            text = v
+        default:
+            return fmt.Errorf("models: cannot scan %T into netip.Addr", dbValue)
+        }
+        addr, err := netip.ParseAddr(text)
+        if err != nil {
+            return err
+        }
+        if fieldValue.Kind() == reflect.Ptr {
+            fieldValue.Set(reflect.ValueOf(&addr))
+        } else {
+            fieldValue.Set(reflect.ValueOf(addr))
+        }
+    }
+    field.ReflectValueOf(ctx, dst).Set(fieldValue)
+    return nil
+}
+
+// Value returns the text form of the address written to the database.
+func (NetIPSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+    switch addr := fieldValue.(type) {
+    case netip.Addr:
+        if addr.IsValid() {
+            return addr.String(), nil
+        }
+    case *netip.Addr:
+        if addr != nil && addr.IsValid() {
+            return addr.String(), nil
+        }
+    }
+    return nil, nil
+}
+`
+
+// netipColumn reports whether a field of goType holds a netip.Addr, which
+// database/sql cannot scan, so it is read through NetIPSerializer.
+func netipColumn(goType string) bool {
+	return strings.TrimPrefix(goType, "*") == "netip.Addr"
+}
+
+// usesNetIP reports whether any generated model has a netip.Addr column.
+func usesNetIP(tables []Table) bool {
+	for _, table := range tables {
+		for _, column := range table.Columns {
+			if containsString(column.GormOptions, "serializer:netip") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+)
+
+const gureguNullImport = "gopkg.in/guregu/null.v4"
+
+// nullableType returns the Go type used for a nullable column in the given
+// null style, together with the import it requires. Slices such as []byte and
+// json.RawMessage already represent NULL as nil and are left untouched, and
+// types without a sql.Null or null.v4 counterpart fall back to a pointer.
+func nullableType(goType, importPath, nullStyle string) (string, string) {
+	if strings.HasPrefix(goType, "*") || strings.HasPrefix(goType, "[]") || goType == "json.RawMessage" {
+		return goType, importPath
+	}
+
+	switch nullStyle {
+	case "sqlnull":
+		switch goType {
+		case "string":
+			return "sql.NullString", "database/sql"
+		case "int", "int64", "uint32":
+			return "sql.NullInt64", "database/sql"
+		case "int32", "uint16":
+			return "sql.NullInt32", "database/sql"
+		case "int16", "int8", "uint8":
+			return "sql.NullInt16", "database/sql"
+		case "float64", "float32":
+			return "sql.NullFloat64", "database/sql"
+		case "bool":
+			return "sql.NullBool", "database/sql"
+		case "time.Time":
+			return "sql.NullTime", "database/sql"
+		}
+	case "guregu":
+		switch goType {
+		case "string":
+			return "null.String", gureguNullImport
+		case "int", "int64", "int32", "int16", "int8", "uint32", "uint16", "uint8":
+			return "null.Int", gureguNullImport
+		case "float64", "float32":
+			return "null.Float", gureguNullImport
+		case "bool":
+			return "null.Bool", gureguNullImport
+		case "time.Time":
+			return "null.Time", gureguNullImport
+		}
+	}
+	return "*" + goType, importPath
+}
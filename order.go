@@ -0,0 +1,107 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// orderColumns sorts the fields of a model. "ordinal" keeps the order of the
+// columns in the table; "alphabetical" puts primary keys first, then the
+// other fields by name; "grouped" puts keys first, then scalar columns, then
+// timestamps, each group in table order; "fieldalign" minimizes padding.
+// Associations always come last.
+func orderColumns(columns []Column, mode string, foreignKeyColumns map[string]bool, enums []EnumType) {
+	switch mode {
+	case "fieldalign":
+		// Decreasing alignment, then size, leaves no padding between fields;
+		// ties keep table order so the result is deterministic
+		stringTypes := map[string]bool{}
+		for _, enum := range enums {
+			stringTypes[enum.Name] = true
+		}
+		sort.SliceStable(columns, func(i, j int) bool {
+			sizeI, alignI := fieldSize(columns[i].Type, stringTypes)
+			sizeJ, alignJ := fieldSize(columns[j].Type, stringTypes)
+			if alignI != alignJ {
+				return alignI > alignJ
+			}
+			return sizeI > sizeJ
+		})
+	case "alphabetical":
+		sort.SliceStable(columns, func(i, j int) bool {
+			if columns[i].PrimaryKey != columns[j].PrimaryKey {
+				return columns[i].PrimaryKey
+			}
+			if columns[i].PrimaryKey {
+				return false
+			}
+			return columns[i].Name < columns[j].Name
+		})
+	case "grouped":
+		group := func(column Column) int {
+			switch {
+			case column.PrimaryKey:
+				return 0
+			case foreignKeyColumns[column.GormName]:
+				return 1
+			case isTimestampColumn(column):
+				return 3
+			default:
+				return 2
+			}
+		}
+		sort.SliceStable(columns, func(i, j int) bool {
+			return group(columns[i]) < group(columns[j])
+		})
+	}
+}
+
+// isTimestampColumn reports whether a column holds a date or time, whatever
+// Go type -time-mode maps it to.
+func isTimestampColumn(column Column) bool {
+	databaseType := strings.ToLower(column.DatabaseType)
+	return strings.Contains(databaseType, "date") || strings.Contains(databaseType, "time")
+}
+
+// fieldSize returns the size and alignment of a field of goType on 64-bit
+// platforms. Types from other packages that are not known are assumed to be
+// word sized.
+func fieldSize(goType string, stringTypes map[string]bool) (int, int) {
+	switch {
+	case strings.HasPrefix(goType, "*") || strings.HasPrefix(goType, "map["):
+		return 8, 8
+	case strings.HasPrefix(goType, "[]") || goType == "json.RawMessage":
+		return 24, 8
+	case stringTypes[goType]:
+		return 16, 8
+	}
+	switch goType {
+	case "string", "interface{}", "any", "EncryptedString", "decimal.Decimal":
+		return 16, 8
+	case "int", "int64", "uint", "uint64", "float64", "uintptr":
+		return 8, 8
+	case "int32", "uint32", "float32", "rune":
+		return 4, 4
+	case "int16", "uint16":
+		return 2, 2
+	case "int8", "uint8", "byte", "bool":
+		return 1, 1
+	case "time.Time", "netip.Addr":
+		return 24, 8
+	case "uuid.UUID":
+		return 16, 1
+	case "sql.NullString", "null.String":
+		return 24, 8
+	case "sql.NullInt64", "sql.NullFloat64", "null.Int", "null.Float":
+		return 16, 8
+	case "sql.NullInt32":
+		return 8, 4
+	case "sql.NullInt16":
+		return 4, 2
+	case "sql.NullBool", "sql.NullByte", "null.Bool":
+		return 2, 1
+	case "sql.NullTime", "null.Time", "gorm.DeletedAt":
+		return 32, 8
+	}
+	return 8, 8
+}
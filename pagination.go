@@ -0,0 +1,240 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+var paginationTemplate = `package models
+
+import (
+{{- range $i, $group := .Imports }}
+{{- if $i }}
+{{ end }}
+{{- range $group }}
+    "{{.}}"
+{{- end }}
+{{- end }}
+)
+
+// {{.TableName}}Page is a page of {{.DBTableName}} rows read by offset.
+type {{.TableName}}Page struct {
+    Items      []{{.TableName}}
+    Page       int
+    PageSize   int
+    Total      int64
+    TotalPages int
+}
+
+// Paginate{{.TableName}} reads a page of the rows db selects, counting pages from
+// 1, ordered by {{.OrderDescription}}.
+func Paginate{{.TableName}}(db *gorm.DB, page, pageSize int) (*{{.TableName}}Page, error) {
+    if page < 1 || pageSize < 1 {
+        return nil, errors.New("models: page and page size must be positive")
+    }
+    query := db.Model(&{{.TableName}}{}).Session(&gorm.Session{})
+    result := &{{.TableName}}Page{Page: page, PageSize: pageSize}
+    if err := query.Count(&result.Total).Error; err != nil {
+        return nil, err
+    }
+    result.TotalPages = int((result.Total + int64(pageSize) - 1) / int64(pageSize))
+    err := query{{range .Order}}.
+        Order(clause.OrderByColumn{Column: clause.Column{Name: "{{.GormName}}"}{{if $.Desc}}, Desc: true{{end}}}){{end}}.
+        Offset((page - 1) * pageSize).
+        Limit(pageSize).
+        Find(&result.Items).Error
+    return result, err
+}
+{{- with .Key }}
+
+// {{$.TableName}}CursorPage is a page of {{$.DBTableName}} rows read after a cursor.
+type {{$.TableName}}CursorPage struct {
+    Items []{{$.TableName}}
+    // NextCursor reads the rows after Items, and is empty on the last page
+    NextCursor string
+}
+
+// {{$.Cursor}} is the position of a row in the keyset order.
+type {{$.Cursor}} struct {
+{{- range . }}
+    {{.Name}} {{.Type}} ` + "`json:\"{{.GormName}}\"`" + `
+{{- end }}
+}
+
+// Paginate{{$.TableName}}After reads up to limit rows db selects after cursor,
+// ordered by {{$.OrderDescription}}. An empty cursor starts at the first row.
+// Unlike offsets, cursors stay stable when rows are inserted or deleted.
+func Paginate{{$.TableName}}After(db *gorm.DB, cursor string, limit int) (*{{$.TableName}}CursorPage, error) {
+    if limit < 1 {
+        return nil, errors.New("models: limit must be positive")
+    }
+    query := db.Model(&{{$.TableName}}{})
+    if cursor != "" {
+        data, err := base64.RawURLEncoding.DecodeString(cursor)
+        if err != nil {
+            return nil, fmt.Errorf("models: invalid cursor: %w", err)
+        }
+        var key {{$.Cursor}}
+        if err := json.Unmarshal(data, &key); err != nil {
+            return nil, fmt.Errorf("models: invalid cursor: %w", err)
+        }
+        query = query.Where({{$.After}})
+    }
+    var items []{{$.TableName}}
+    err := query{{range $.Order}}.
+        Order(clause.OrderByColumn{Column: clause.Column{Name: "{{.GormName}}"}{{if $.Desc}}, Desc: true{{end}}}){{end}}.
+        Limit(limit + 1).
+        Find(&items).Error
+    if err != nil {
+        return nil, err
+    }
+    result := &{{$.TableName}}CursorPage{Items: items}
+    if len(items) > limit {
+        result.Items = items[:limit]
+        last := result.Items[limit-1]
+        data, err := json.Marshal({{$.Cursor}}{ {{- range $i, $column := . }}{{if $i}}, {{end}}{{.Name}}: last.{{.Name}}{{end -}} })
+        if err != nil {
+            return nil, err
+        }
+        result.NextCursor = base64.RawURLEncoding.EncodeToString(data)
+    }
+    return result, nil
+}
+{{- end }}
+`
+
+// Pagination is the data of a generated <Model>Pagination.go file.
+type Pagination struct {
+	TableName   string
+	DBTableName string
+	Imports     [][]string
+	// Order is the sort column followed by the primary key, if they differ
+	Order []QueryColumn
+	Desc  bool
+	// Key holds the columns of the cursor, and is empty when the table has
+	// no single-column primary key to break ties
+	Key    []QueryColumn
+	Cursor string
+	// After is the condition selecting the rows after key
+	After string
+}
+
+// OrderDescription describes the order of the pages in the doc comments.
+func (pagination Pagination) OrderDescription() string {
+	var names []string
+	for _, column := range pagination.Order {
+		names = append(names, column.GormName)
+	}
+	if pagination.Desc {
+		return strings.Join(names, ", ") + " descending"
+	}
+	return strings.Join(names, ", ")
+}
+
+// buildPagination derives the pagination helpers of a table. Pages are
+// ordered by sortColumn, e.g. "created_at" or "created_at desc", and then by
+// the primary key, or by the primary key alone when sortColumn is empty.
+func buildPagination(table Table, sortColumn string, report *Report) Pagination {
+	pagination := Pagination{
+		TableName:   table.TableName,
+		DBTableName: table.DBTableName,
+		Cursor:      lowerFirst(table.TableName) + "Cursor",
+	}
+	fields := strings.Fields(sortColumn)
+	if len(fields) == 2 && strings.EqualFold(fields[1], "desc") {
+		pagination.Desc = true
+	} else if len(fields) > 1 && !strings.EqualFold(fields[1], "asc") || len(fields) > 2 {
+		report.warnf("pagination column %q of %s is not a column with an optional asc or desc", sortColumn, table.DBTableName)
+		fields = nil
+	}
+
+	var primaryKeys []QueryColumn
+	var sortKey *QueryColumn
+	found := false
+	keyset := true
+	for _, column := range table.Columns {
+		valueType, ok := queryValueType(column.Type)
+		// Serialized values compare as stored, not as their Go values
+		ok = ok && column.Serializer == ""
+		queryColumn := QueryColumn{Name: column.Name, GormName: column.GormName, Type: valueType}
+		if column.PrimaryKey {
+			primaryKeys = append(primaryKeys, queryColumn)
+			// Null or wrapped keys cannot be compared to a cursor value
+			keyset = keyset && ok && valueType == column.Type
+		}
+		if len(fields) > 0 && column.GormName == fields[0] {
+			found = true
+			switch {
+			case column.Ignored:
+				report.warnf("pagination column %s.%s is ignored and not used", table.DBTableName, column.GormName)
+			case column.Nullable || !ok || valueType != column.Type:
+				report.warnf("pagination column %s.%s is nullable or not comparable and not used", table.DBTableName, column.GormName)
+			default:
+				sortKey = &queryColumn
+			}
+		}
+	}
+	if len(fields) > 0 && !found {
+		report.warnf("pagination column %s.%s does not exist", table.DBTableName, fields[0])
+	}
+	if sortKey == nil {
+		pagination.Desc = false
+	} else if !containsQueryColumn(primaryKeys, sortKey.GormName) {
+		pagination.Order = append(pagination.Order, *sortKey)
+	}
+	pagination.Order = append(pagination.Order, primaryKeys...)
+	if keyset && len(primaryKeys) == 1 {
+		pagination.Key = pagination.Order
+		pagination.After = keysetCondition(pagination.Order, pagination.Desc)
+	}
+
+	imports := []string{"errors"}
+	if pagination.Key != nil {
+		imports = append(imports, "encoding/base64", "encoding/json", "fmt")
+		for _, column := range pagination.Key {
+			if column.Type == "time.Time" && !containsString(imports, "time") {
+				imports = append(imports, "time")
+			}
+		}
+	}
+	imports = append(imports, "gorm.io/gorm", "gorm.io/gorm/clause")
+	sort.Strings(imports)
+	pagination.Imports = Table{ModelImports: imports}.ImportGroups()
+	return pagination
+}
+
+// keysetCondition returns the condition selecting the rows after key in the
+// order of columns, e.g. created_at > key.CreatedAt OR (created_at =
+// key.CreatedAt AND id > key.Id).
+func keysetCondition(columns []QueryColumn, desc bool) string {
+	comparison := "clause.Gt"
+	if desc {
+		comparison = "clause.Lt"
+	}
+	var conditions []string
+	for i, column := range columns {
+		var terms []string
+		for _, previous := range columns[:i] {
+			terms = append(terms, `clause.Eq{Column: clause.Column{Name: "`+previous.GormName+`"}, Value: key.`+previous.Name+`}`)
+		}
+		terms = append(terms, comparison+`{Column: clause.Column{Name: "`+column.GormName+`"}, Value: key.`+column.Name+`}`)
+		if len(terms) == 1 {
+			conditions = append(conditions, terms[0])
+		} else {
+			conditions = append(conditions, "clause.And("+strings.Join(terms, ", ")+")")
+		}
+	}
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+	return "clause.Or(\n" + strings.Join(conditions, ",\n") + ",\n)"
+}
+
+func containsQueryColumn(columns []QueryColumn, gormName string) bool {
+	for _, column := range columns {
+		if column.GormName == gormName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+func TestKeysetCondition(t *testing.T) {
+	id := QueryColumn{Name: "Id", GormName: "id", Type: "int"}
+	createdAt := QueryColumn{Name: "CreatedAt", GormName: "created_at", Type: "time.Time"}
+	tests := []struct {
+		name    string
+		columns []QueryColumn
+		desc    bool
+		want    string
+	}{
+		{
+			name:    "primary key",
+			columns: []QueryColumn{id},
+			want:    `clause.Gt{Column: clause.Column{Name: "id"}, Value: key.Id}`,
+		},
+		{
+			name:    "primary key descending",
+			columns: []QueryColumn{id},
+			desc:    true,
+			want:    `clause.Lt{Column: clause.Column{Name: "id"}, Value: key.Id}`,
+		},
+		{
+			name:    "sort column and primary key",
+			columns: []QueryColumn{createdAt, id},
+			want: `clause.Or(
+clause.Gt{Column: clause.Column{Name: "created_at"}, Value: key.CreatedAt},
+clause.And(clause.Eq{Column: clause.Column{Name: "created_at"}, Value: key.CreatedAt}, clause.Gt{Column: clause.Column{Name: "id"}, Value: key.Id}),
+)`,
+		},
+		{
+			name:    "sort column and primary key descending",
+			columns: []QueryColumn{createdAt, id},
+			desc:    true,
+			want: `clause.Or(
+clause.Lt{Column: clause.Column{Name: "created_at"}, Value: key.CreatedAt},
+clause.And(clause.Eq{Column: clause.Column{Name: "created_at"}, Value: key.CreatedAt}, clause.Lt{Column: clause.Column{Name: "id"}, Value: key.Id}),
+)`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := keysetCondition(test.columns, test.desc); got != test.want {
+				t.Errorf("keysetCondition() =\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
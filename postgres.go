@@ -0,0 +1,34 @@
+package main
+
+// postgresColumnType maps a Postgres column type (as reported by udt_name) to a
+// Go type and the import it requires, if any. Integer types keep their storage
+// width so serial and identity columns round-trip through AutoMigrate. ok is
+// false when an unknown type falls back to string.
+func postgresColumnType(databaseType string) (string, string, bool) {
+	switch databaseType {
+	case "int2", "smallserial":
+		return "int16", "", true
+	case "int4", "serial":
+		return "int32", "", true
+	case "int8", "bigserial":
+		return "int64", "", true
+	case "float4":
+		return "float32", "", true
+	case "float8":
+		return "float64", "", true
+	case "numeric", "money":
+		return "string", "", true // or use a custom decimal type
+	case "bool":
+		return "bool", "", true
+	case "timestamp", "timestamptz", "date", "time", "timetz":
+		return "time.Time", "time", true
+	case "char", "bpchar", "varchar", "text", "citext", "uuid":
+		return "string", "", true
+	case "bytea":
+		return "[]byte", "", true
+	case "json", "jsonb":
+		return "json.RawMessage", "encoding/json", true
+	default:
+		return "string", "", false // default to string for any other types
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// runProfiles generates several profiles of the config file one after
+// another. Each profile is run by its own generator process with the
+// arguments of this one, so their connections, settings and reports never
+// mix, while the rules of the config file apply to all of them. The run
+// fails after the others when a profile fails.
+func runProfiles(command string, names []string, config Config) {
+	for _, name := range names {
+		if _, ok := config.Profiles[name]; !ok {
+			log.Fatalf("Profile %s is not defined in the config file", name)
+		}
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		log.Fatalf("Failed to find the generator executable: %v", err)
+	}
+
+	args := withoutProfileFlag(os.Args[1:])
+	if command != "" {
+		args = append([]string{command}, args...)
+	}
+	var failed []string
+	for _, name := range names {
+		// Headings go to stderr, so stdout only holds the reports
+		fmt.Fprintf(os.Stderr, "==> Profile %s\n", name)
+		cmd := exec.Command(executable, append(args, "-profile="+name)...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) > 0 {
+		log.Fatalf("Failed to generate profiles %s", strings.Join(failed, ", "))
+	}
+}
+
+// withoutProfileFlag returns the command-line arguments without -profile
+// and its value.
+func withoutProfileFlag(args []string) []string {
+	var kept []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--" {
+			return append(kept, args[i:]...)
+		}
+		name := strings.TrimLeft(args[i], "-")
+		if strings.HasPrefix(args[i], "-") && name == "profile" {
+			i++
+			continue
+		}
+		if strings.HasPrefix(args[i], "-") && strings.HasPrefix(name, "profile=") {
+			continue
+		}
+		kept = append(kept, args[i])
+	}
+	return kept
+}
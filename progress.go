@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Progress reports which table is being generated, so long runs over large
+// schemas don't look hung. On a terminal it keeps a single status line up to
+// date; otherwise, e.g. in CI logs, it prints a line per table.
+type Progress struct {
+	out      io.Writer
+	total    int
+	done     int
+	terminal bool
+}
+
+// newProgress returns a Progress for total tables writing to stderr, or nil
+// when quiet. A nil *Progress reports nothing.
+func newProgress(total int, quiet bool) *Progress {
+	if quiet {
+		return nil
+	}
+	terminal := false
+	if info, err := os.Stderr.Stat(); err == nil {
+		terminal = info.Mode()&os.ModeCharDevice != 0
+	}
+	return &Progress{out: os.Stderr, total: total, terminal: terminal}
+}
+
+// Start reports that generation of a table has started.
+func (p *Progress) Start(tableName string) {
+	if p == nil {
+		return
+	}
+	p.done++
+	width := len(fmt.Sprint(p.total))
+	if p.terminal {
+		// Clear the previous status line before writing the new one
+		fmt.Fprintf(p.out, "\r\033[K[%*d/%d] %3d%% %s", width, p.done, p.total, p.done*100/p.total, tableName)
+	} else {
+		fmt.Fprintf(p.out, "[%*d/%d] %s\n", width, p.done, p.total, tableName)
+	}
+}
+
+// Finish ends the status line once all tables are generated.
+func (p *Progress) Finish() {
+	if p == nil || !p.terminal {
+		return
+	}
+	fmt.Fprintf(p.out, "\r\033[K")
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var queryTemplate = `package models
+
+import (
+{{- range .Imports }}
+	"{{.}}"
+{{- end }}
+)
+
+// {{.TableName}}QueryBuilder builds type-safe queries on the {{.DBTableName}} table.
+type {{.TableName}}QueryBuilder struct {
+    db *gorm.DB
+}
+
+// {{.TableName}}Query starts a query on the {{.DBTableName}} table.
+func {{.TableName}}Query(db *gorm.DB) *{{.TableName}}QueryBuilder {
+    return &{{.TableName}}QueryBuilder{db: db.Model(&{{.TableName}}{})}
+}
+{{- range .Filters }}
+
+func (q *{{$.TableName}}QueryBuilder) Where{{.Name}}Eq(value {{.Type}}) *{{$.TableName}}QueryBuilder {
+    q.db = q.db.Where(clause.Eq{Column: clause.Column{Name: "{{.GormName}}"}, Value: value})
+    return q
+}
+
+func (q *{{$.TableName}}QueryBuilder) Where{{.Name}}In(values ...{{.Type}}) *{{$.TableName}}QueryBuilder {
+    in := make([]interface{}, len(values))
+    for i, value := range values {
+        in[i] = value
+    }
+    q.db = q.db.Where(clause.IN{Column: clause.Column{Name: "{{.GormName}}"}, Values: in})
+    return q
+}
+{{- end }}
+{{- range .Orders }}
+
+func (q *{{$.TableName}}QueryBuilder) OrderBy{{.Name}}Asc() *{{$.TableName}}QueryBuilder {
+    q.db = q.db.Order(clause.OrderByColumn{Column: clause.Column{Name: "{{.GormName}}"}})
+    return q
+}
+
+func (q *{{$.TableName}}QueryBuilder) OrderBy{{.Name}}Desc() *{{$.TableName}}QueryBuilder {
+    q.db = q.db.Order(clause.OrderByColumn{Column: clause.Column{Name: "{{.GormName}}"}, Desc: true})
+    return q
+}
+{{- end }}
+
+func (q *{{.TableName}}QueryBuilder) Limit(limit int) *{{.TableName}}QueryBuilder {
+    q.db = q.db.Limit(limit)
+    return q
+}
+
+func (q *{{.TableName}}QueryBuilder) Offset(offset int) *{{.TableName}}QueryBuilder {
+    q.db = q.db.Offset(offset)
+    return q
+}
+
+// DB returns the underlying query for anything the builder does not cover.
+func (q *{{.TableName}}QueryBuilder) DB() *gorm.DB {
+    return q.db
+}
+
+func (q *{{.TableName}}QueryBuilder) Find() ([]{{.TableName}}, error) {
+    var models []{{.TableName}}
+    err := q.db.Find(&models).Error
+    return models, err
+}
+
+func (q *{{.TableName}}QueryBuilder) First() (*{{.TableName}}, error) {
+    var model {{.TableName}}
+    if err := q.db.First(&model).Error; err != nil {
+        return nil, err
+    }
+    return &model, nil
+}
+
+func (q *{{.TableName}}QueryBuilder) Count() (int64, error) {
+    var count int64
+    err := q.db.Count(&count).Error
+    return count, err
+}
+{{- range .Counts }}
+
+// Count{{$.TableName}}By{{.Name}} counts the {{$.DBTableName}} rows whose {{.GormName}} is value.
+func Count{{$.TableName}}By{{.Name}}(db *gorm.DB, value {{.Type}}) (int64, error) {
+    return {{$.TableName}}Query(db).Where{{.Name}}Eq(value).Count()
+}
+{{- end }}
+{{- range .Exists }}
+
+// Exists{{$.TableName}}By{{.Name}} reports whether a {{$.DBTableName}} row has {{.GormName}} value.
+func Exists{{$.TableName}}By{{.Name}}(db *gorm.DB, value {{.Type}}) (bool, error) {
+    var found int
+    err := {{$.TableName}}Query(db).Where{{.Name}}Eq(value).DB().Select("1").Limit(1).Scan(&found).Error
+    return found == 1, err
+}
+{{- end }}
+`
+
+// Index is an index of a table, with its columns in index order.
+type Index struct {
+	Name       string
+	Columns    []string
+	Unique     bool
+	PrimaryKey bool
+	// Class is FULLTEXT or SPATIAL for those indexes on MySQL
+	Class string
+	// Lengths are the prefix lengths of the columns on MySQL, 0 for columns
+	// indexed whole
+	Lengths []int
+}
+
+// QueryColumn is a column the query builder can filter or order on.
+type QueryColumn struct {
+	Name     string
+	GormName string
+	Type     string
+}
+
+type QueryBuilder struct {
+	TableName   string
+	DBTableName string
+	Imports     []string
+	Filters     []QueryColumn
+	Orders      []QueryColumn
+	// Counts are the filtered columns that are not unique, and Exists the
+	// unique ones, i.e. primary keys and single-column unique indexes
+	Counts []QueryColumn
+	Exists []QueryColumn
+}
+
+// loadIndexes returns the indexes of a table. Drivers without index
+// introspection, like ClickHouse, report no indexes.
+func loadIndexes(db *gorm.DB, driver, tableName string) ([]Index, error) {
+	if driver == "clickhouse" {
+		return nil, nil
+	}
+
+	gormIndexes, err := db.Migrator().GetIndexes(tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	var indexes []Index
+	for _, gormIndex := range gormIndexes {
+		unique, _ := gormIndex.Unique()
+		primaryKey, _ := gormIndex.PrimaryKey()
+		// The Postgres driver leaves out unique constraints and orders
+		// columns by their position in the table, so unique indexes are
+		// read on their own
+		if driver == "postgres" && unique && !primaryKey {
+			continue
+		}
+		indexes = append(indexes, Index{
+			Name:       gormIndex.Name(),
+			Columns:    gormIndex.Columns(),
+			Unique:     unique,
+			PrimaryKey: primaryKey,
+		})
+	}
+	if driver == "mysql" || driver == "tidb" {
+		if err := mysqlIndexDetails(db, tableName, indexes); err != nil {
+			return nil, err
+		}
+	}
+	if driver == "postgres" {
+		unique, err := postgresUniqueIndexes(db, tableName)
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, unique...)
+		sort.Slice(indexes, func(i, j int) bool { return indexes[i].Name < indexes[j].Name })
+	}
+	return indexes, nil
+}
+
+// buildQueryBuilder derives the query builder of a table. Where methods are
+// generated for columns leading an index, so every generated filter can use
+// one; OrderBy methods also cover timestamp columns. The indexed columns also
+// get Exists helpers when they are unique and Count helpers otherwise.
+func buildQueryBuilder(table Table) QueryBuilder {
+	builder := QueryBuilder{
+		TableName:   table.TableName,
+		DBTableName: table.DBTableName,
+		Imports:     []string{"gorm.io/gorm", "gorm.io/gorm/clause"},
+	}
+
+	leading := leadingIndexColumns(table)
+	unique := map[string]bool{}
+	for _, index := range table.Indexes {
+		if (index.Unique || index.PrimaryKey) && len(index.Columns) == 1 {
+			unique[index.Columns[0]] = true
+		}
+	}
+	primaryKeys := 0
+	for _, column := range table.Columns {
+		if column.PrimaryKey {
+			primaryKeys++
+		}
+	}
+	for _, column := range table.Columns {
+		valueType, ok := queryValueType(column.Type)
+		if !ok || column.Ignored || column.Serializer != "" {
+			continue
+		}
+		queryColumn := QueryColumn{Name: column.Name, GormName: column.GormName, Type: valueType}
+
+		indexed := leading[column.GormName] || column.PrimaryKey
+		if indexed {
+			builder.Filters = append(builder.Filters, queryColumn)
+			if unique[column.GormName] || column.PrimaryKey && primaryKeys == 1 {
+				builder.Exists = append(builder.Exists, queryColumn)
+			} else {
+				builder.Counts = append(builder.Counts, queryColumn)
+			}
+			if valueType == "time.Time" && !strings.Contains(strings.Join(builder.Imports, ","), "time") {
+				builder.Imports = append([]string{"time"}, builder.Imports...)
+			}
+		}
+		if indexed || valueType == "time.Time" {
+			builder.Orders = append(builder.Orders, queryColumn)
+		}
+	}
+	return builder
+}
+
+// leadingIndexColumns returns the columns leading an index of a table, which
+// conditions on them alone can use.
+func leadingIndexColumns(table Table) map[string]bool {
+	leading := map[string]bool{}
+	for _, index := range table.Indexes {
+		if len(index.Columns) > 0 {
+			leading[index.Columns[0]] = true
+		}
+	}
+	return leading
+}
+
+// queryValueType returns the type of the value a filter on a field of goType
+// takes. Pointers and null types filter on their underlying type; types that
+// cannot be compared in SQL, such as []byte and JSON, or that need imports of
+// their own, are not supported.
+func queryValueType(goType string) (string, bool) {
+	goType = strings.TrimPrefix(goType, "*")
+	switch goType {
+	case "sql.NullString", "null.String":
+		return "string", true
+	case "sql.NullInt64", "null.Int":
+		return "int64", true
+	case "sql.NullInt32":
+		return "int32", true
+	case "sql.NullInt16":
+		return "int16", true
+	case "sql.NullFloat64", "null.Float":
+		return "float64", true
+	case "sql.NullBool", "null.Bool":
+		return "bool", true
+	case "sql.NullTime", "null.Time":
+		return "time.Time", true
+	case "EncryptedString":
+		// Ciphertexts are usually randomized, so equality filters cannot match
+		return "", false
+	}
+
+	switch {
+	case goType == "time.Time":
+		return goType, true
+	case strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[") || strings.Contains(goType, "."):
+		return "", false
+	default:
+		return goType, true
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// grantPrivileges matches the privileges and object of a GRANT statement of
+// SHOW GRANTS, e.g. GRANT SELECT, INSERT ON `app`.* TO `app`@`%`
+var grantPrivileges = regexp.MustCompile(`(?i)^GRANT (.+?) ON (\S+) TO `)
+
+// grantColumns matches the column list of a column privilege, e.g. SELECT
+// (`id`, `name`)
+var grantColumns = regexp.MustCompile(`\s*\([^)]*\)`)
+
+// readPrivileges are the privileges of SHOW GRANTS that cannot modify data
+var readPrivileges = map[string]bool{
+	"USAGE":              true,
+	"SELECT":             true,
+	"SHOW VIEW":          true,
+	"SHOW DATABASES":     true,
+	"PROCESS":            true,
+	"REPLICATION CLIENT": true,
+	// ClickHouse
+	"SHOW":              true,
+	"SHOW TABLES":       true,
+	"SHOW COLUMNS":      true,
+	"SHOW DICTIONARIES": true,
+}
+
+// postgresWritePrivilegesQuery selects the superuser attribute and the
+// privileges on the tables of the current schema that modify data
+const postgresWritePrivilegesQuery = `SELECT 'SUPERUSER' FROM pg_roles WHERE rolname = current_user AND rolsuper
+UNION ALL
+SELECT p || ' ON ' || c.relname
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+CROSS JOIN unnest(ARRAY['INSERT', 'UPDATE', 'DELETE', 'TRUNCATE']) p
+WHERE n.nspname = current_schema() AND c.relkind IN ('r', 'p') AND has_table_privilege(c.oid, p)`
+
+// readOnlyDSNParam returns the DSN parameter that makes every session of the
+// connection pool read-only, or "" when the driver has none. legacy selects
+// tx_read_only, the only name MariaDB knows before 11.1.
+func readOnlyDSNParam(driver string, legacy bool) string {
+	switch driver {
+	case "mysql":
+		if legacy {
+			return "tx_read_only=1"
+		}
+		return "transaction_read_only=1"
+	case "postgres", "cockroach":
+		return "default_transaction_read_only=on"
+	case "clickhouse":
+		// Unlike readonly=1, readonly=2 still accepts the settings the
+		// driver sends with each query
+		return "readonly=2"
+	}
+	// TiDB only accepts read-only transactions as a no-op
+	return ""
+}
+
+// isUnknownReadOnlyVariable reports whether connecting failed because the
+// server does not know transaction_read_only.
+func isUnknownReadOnlyVariable(err error) bool {
+	return strings.Contains(err.Error(), "Unknown system variable 'transaction_read_only'")
+}
+
+// verifyReadOnly checks that the session cannot modify data. The session
+// must be read-only except on TiDB, where the grants of the user are checked
+// instead. With checkGrants, the user must also lack write privileges, so
+// the guarantee does not rest on the session alone.
+func verifyReadOnly(db *gorm.DB, driver string, legacy, checkGrants bool) error {
+	readOnly, err := sessionReadOnly(db, driver, legacy)
+	if err != nil {
+		return fmt.Errorf("failed to verify the session is read-only: %w", err)
+	}
+	if !readOnly && driver != "tidb" {
+		return errors.New("the session could not be made read-only")
+	}
+	if readOnly && !checkGrants {
+		return nil
+	}
+
+	writes, err := writePrivileges(db, driver)
+	if err != nil {
+		return fmt.Errorf("failed to check the grants of the user: %w", err)
+	}
+	if len(writes) > 5 {
+		writes = append(writes[:5], fmt.Sprintf("and %d more", len(writes)-5))
+	}
+	if len(writes) > 0 {
+		return fmt.Errorf("the user can modify data: %s", strings.Join(writes, ", "))
+	}
+	return nil
+}
+
+// sessionReadOnly reports whether the session rejects writes.
+func sessionReadOnly(db *gorm.DB, driver string, legacy bool) (bool, error) {
+	switch driver {
+	case "mysql":
+		variable := "transaction_read_only"
+		if legacy {
+			variable = "tx_read_only"
+		}
+		var readOnly int
+		err := db.Raw("SELECT @@" + variable).Scan(&readOnly).Error
+		return readOnly == 1, err
+	case "postgres", "cockroach":
+		var readOnly string
+		err := db.Raw("SHOW transaction_read_only").Scan(&readOnly).Error
+		return readOnly == "on", err
+	case "clickhouse":
+		var readOnly uint8
+		err := db.Raw("SELECT toUInt8(getSetting('readonly'))").Scan(&readOnly).Error
+		return readOnly != 0, err
+	}
+	return false, nil
+}
+
+// writePrivileges returns the privileges of the user that can modify data,
+// e.g. INSERT ON `app`.*. On MySQL, TiDB and ClickHouse granted roles are
+// included, as SHOW GRANTS does not list their privileges.
+func writePrivileges(db *gorm.DB, driver string) ([]string, error) {
+	if driver == "postgres" || driver == "cockroach" {
+		var writes []string
+		err := db.Raw(postgresWritePrivilegesQuery).Scan(&writes).Error
+		return writes, err
+	}
+
+	var grants []string
+	if err := db.Raw("SHOW GRANTS").Scan(&grants).Error; err != nil {
+		return nil, err
+	}
+	var writes []string
+	for _, grant := range grants {
+		writes = append(writes, grantWrites(grant)...)
+	}
+	return writes, nil
+}
+
+// grantWrites returns the privileges of a statement of SHOW GRANTS that can
+// modify data. Partial revokes only take privileges away.
+func grantWrites(grant string) []string {
+	grant = grantColumns.ReplaceAllString(strings.TrimSpace(grant), "")
+	if strings.HasPrefix(strings.ToUpper(grant), "REVOKE ") {
+		return nil
+	}
+	match := grantPrivileges.FindStringSubmatch(grant)
+	if match == nil {
+		role, _, _ := strings.Cut(strings.TrimPrefix(grant, "GRANT "), " TO ")
+		return []string{"role " + role}
+	}
+	var writes []string
+	for _, privilege := range strings.Split(match[1], ",") {
+		privilege = strings.ToUpper(strings.TrimSpace(privilege))
+		if !readPrivileges[privilege] {
+			writes = append(writes, privilege+" ON "+match[2])
+		}
+	}
+	return writes
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/jinzhu/inflection"
+	"gorm.io/gorm"
+)
+
+type ForeignKey struct {
+	Name             string
+	Table            string
+	Column           string
+	ReferencedTable  string
+	ReferencedColumn string
+	OnUpdate         string
+	OnDelete         string
+	Unique           bool
+}
+
+type Association struct {
+	Name        string
+	Type        string
+	GormOptions []string
+}
+
+const mysqlForeignKeysQuery = `SELECT k.CONSTRAINT_NAME, k.TABLE_NAME, k.COLUMN_NAME, k.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME, r.UPDATE_RULE, r.DELETE_RULE
+FROM information_schema.KEY_COLUMN_USAGE k
+JOIN information_schema.REFERENTIAL_CONSTRAINTS r ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.TABLE_NAME = k.TABLE_NAME AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+WHERE k.TABLE_SCHEMA = DATABASE() AND k.REFERENCED_TABLE_NAME IS NOT NULL
+ORDER BY k.TABLE_NAME, k.CONSTRAINT_NAME, k.ORDINAL_POSITION`
+
+const postgresForeignKeysQuery = `SELECT tc.constraint_name, kcu.table_name, kcu.column_name, ccu.table_name, ccu.column_name, rc.update_rule, rc.delete_rule
+FROM information_schema.table_constraints tc
+JOIN information_schema.key_column_usage kcu ON kcu.constraint_schema = tc.constraint_schema AND kcu.constraint_name = tc.constraint_name
+JOIN information_schema.referential_constraints rc ON rc.constraint_schema = tc.constraint_schema AND rc.constraint_name = tc.constraint_name
+JOIN information_schema.constraint_column_usage ccu ON ccu.constraint_schema = tc.constraint_schema AND ccu.constraint_name = tc.constraint_name
+WHERE tc.constraint_type = 'FOREIGN KEY' AND tc.table_schema = current_schema()
+ORDER BY kcu.table_name, tc.constraint_name, kcu.ordinal_position`
+
+// loadForeignKeys returns the single-column foreign keys of the current
+// schema. Composite foreign keys are skipped since they cannot be expressed as
+// a single association field.
+func loadForeignKeys(db *gorm.DB, driver string) ([]ForeignKey, error) {
+	var query string
+	switch driver {
+	case "mysql", "tidb":
+		query = mysqlForeignKeysQuery
+	case "postgres", "cockroach":
+		query = postgresForeignKeysQuery
+	default:
+		return nil, nil
+	}
+
+	rows, err := db.Raw(query).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var foreignKeys []ForeignKey
+	columnCounts := map[string]int{}
+	for rows.Next() {
+		var fk ForeignKey
+		if err := rows.Scan(&fk.Name, &fk.Table, &fk.Column, &fk.ReferencedTable, &fk.ReferencedColumn, &fk.OnUpdate, &fk.OnDelete); err != nil {
+			return nil, err
+		}
+		columnCounts[fk.Table+"."+fk.Name]++
+		foreignKeys = append(foreignKeys, fk)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var singleColumn []ForeignKey
+	for _, fk := range foreignKeys {
+		if columnCounts[fk.Table+"."+fk.Name] == 1 {
+			singleColumn = append(singleColumn, fk)
+		}
+	}
+	return singleColumn, nil
+}
+
+// buildAssociations builds the associations of a table from foreign keys: a
+// belongs-to association for every foreign key declared on the table, and a
+// has-many (or has-one, when the foreign key column is unique) association for
+// every foreign key referencing it. A self-referential foreign key produces
+// both sides, e.g. Parent *Category and Children []Category. Associations whose
+// name would collide with another field are skipped.
+func buildAssociations(tableName string, columns []Column, foreignKeys []ForeignKey) []Association {
+	fieldNames := map[string]bool{}
+	for _, column := range columns {
+		fieldNames[column.Name] = true
+	}
+
+	var associations []Association
+	for _, fk := range foreignKeys {
+		if fk.Table != tableName {
+			continue
+		}
+
+		name := belongsToName(fk)
+		if fieldNames[name] {
+			continue
+		}
+		fieldNames[name] = true
+
+		associations = append(associations, Association{
+			Name: name,
+			Type: "*" + modelName(fk.ReferencedTable),
+			GormOptions: []string{
+				"foreignKey:" + camelCase(fk.Column),
+				"references:" + camelCase(fk.ReferencedColumn),
+			},
+		})
+	}
+
+	// Count references per child table so several foreign keys from the same
+	// table, e.g. sender_id and recipient_id, get distinct names
+	references := map[string]int{}
+	for _, fk := range foreignKeys {
+		if fk.ReferencedTable == tableName {
+			references[fk.Table]++
+		}
+	}
+
+	for _, fk := range foreignKeys {
+		if fk.ReferencedTable != tableName {
+			continue
+		}
+
+		name := referencedByName(fk, references[fk.Table] > 1)
+		if fieldNames[name] {
+			continue
+		}
+		fieldNames[name] = true
+
+		associationType := "[]" + modelName(fk.Table)
+		if fk.Unique {
+			associationType = "*" + modelName(fk.Table)
+		}
+
+		// GORM ignores the constraint of a belongs-to association when the
+		// referenced model has the inverse side, so the actions go here
+		gormOptions := []string{
+			"foreignKey:" + camelCase(fk.Column),
+			"references:" + camelCase(fk.ReferencedColumn),
+		}
+		if constraint := constraintOption(fk); constraint != "" {
+			gormOptions = append(gormOptions, constraint)
+		}
+
+		associations = append(associations, Association{
+			Name:        name,
+			Type:        associationType,
+			GormOptions: gormOptions,
+		})
+	}
+	return associations
+}
+
+// belongsToName names the belongs-to side of a foreign key after its column,
+// e.g. user_id -> User.
+func belongsToName(fk ForeignKey) string {
+	return camelCase(strings.TrimSuffix(fk.Column, "_id"))
+}
+
+// referencedByName names the has-one or has-many side of a foreign key after
+// the referencing model, e.g. Posts or Profile. Self-referential parent keys
+// use Children or Child, and qualified names such as SenderMessages are used
+// when the referencing table has several foreign keys to the same table.
+func referencedByName(fk ForeignKey, qualified bool) string {
+	name := modelName(fk.Table)
+	if !fk.Unique {
+		name = inflection.Plural(name)
+	}
+
+	if fk.Table == fk.ReferencedTable {
+		if belongsToName(fk) == "Parent" {
+			if fk.Unique {
+				return "Child"
+			}
+			return "Children"
+		}
+		qualified = true
+	}
+	if qualified {
+		return belongsToName(fk) + name
+	}
+	return name
+}
+
+// polymorphicAssociations builds the associations of a table that owns
+// polymorphic associations, e.g. Comments []Comment with a
+// polymorphic:Commentable tag on posts. Associations whose name would collide
+// with another field are skipped.
+func polymorphicAssociations(tableName string, columns []Column, associations []Association, polymorphics []PolymorphicConfig) []Association {
+	fieldNames := map[string]bool{}
+	for _, column := range columns {
+		fieldNames[column.Name] = true
+	}
+	for _, association := range associations {
+		fieldNames[association.Name] = true
+	}
+
+	var polymorphicAssociations []Association
+	for _, polymorphic := range polymorphics {
+		for _, owner := range polymorphic.Owners {
+			if owner.Table != tableName {
+				continue
+			}
+
+			name := inflection.Plural(modelName(polymorphic.Table))
+			associationType := "[]" + modelName(polymorphic.Table)
+			if polymorphic.HasOne {
+				name = modelName(polymorphic.Table)
+				associationType = "*" + modelName(polymorphic.Table)
+			}
+			if fieldNames[name] {
+				continue
+			}
+			fieldNames[name] = true
+
+			gormOptions := []string{"polymorphic:" + camelCase(polymorphic.Name)}
+			if owner.Value != "" {
+				gormOptions = append(gormOptions, "polymorphicValue:"+owner.Value)
+			}
+
+			polymorphicAssociations = append(polymorphicAssociations, Association{
+				Name:        name,
+				Type:        associationType,
+				GormOptions: gormOptions,
+			})
+		}
+	}
+	return polymorphicAssociations
+}
+
+// markUniqueForeignKeys flags foreign keys whose column carries a unique
+// constraint, which makes the referencing side a has-one association.
+func markUniqueForeignKeys(db *gorm.DB, foreignKeys []ForeignKey) error {
+	uniqueColumns := map[string]map[string]bool{}
+	for i, fk := range foreignKeys {
+		if _, ok := uniqueColumns[fk.Table]; !ok {
+			columnTypes, err := db.Migrator().ColumnTypes(fk.Table)
+			if err != nil {
+				return err
+			}
+			uniqueColumns[fk.Table] = map[string]bool{}
+			for _, columnType := range columnTypes {
+				if unique, ok := columnType.Unique(); ok && unique {
+					uniqueColumns[fk.Table][columnType.Name()] = true
+				}
+			}
+		}
+		foreignKeys[i].Unique = uniqueColumns[fk.Table][fk.Column]
+	}
+	return nil
+}
+
+// constraintOption renders the referential actions of a foreign key as a gorm
+// constraint option. NO ACTION is the default for both actions and is left out.
+func constraintOption(fk ForeignKey) string {
+	var actions []string
+	if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+		actions = append(actions, "OnUpdate:"+fk.OnUpdate)
+	}
+	if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+		actions = append(actions, "OnDelete:"+fk.OnDelete)
+	}
+	if len(actions) == 0 {
+		return ""
+	}
+	return "constraint:" + strings.Join(actions, ",")
+}
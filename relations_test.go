@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+// relationUser and relationPost declare the associations buildAssociations
+// generates for posts.user_id referencing users.id, which
+// TestAssociationConstraint checks the tags of.
+type relationUser struct {
+	Id    int            `gorm:"column:id;primaryKey"`
+	Posts []relationPost `gorm:"foreignKey:UserId;references:Id;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+}
+
+type relationPost struct {
+	Id     int           `gorm:"column:id;primaryKey"`
+	UserId int           `gorm:"column:user_id"`
+	User   *relationUser `gorm:"foreignKey:UserId;references:Id"`
+}
+
+func TestAssociationConstraint(t *testing.T) {
+	foreignKeys := []ForeignKey{{
+		Table:            "posts",
+		Column:           "user_id",
+		ReferencedTable:  "users",
+		ReferencedColumn: "id",
+		OnUpdate:         "CASCADE",
+		OnDelete:         "SET NULL",
+	}}
+	tags := map[string]string{}
+	for _, table := range []string{"users", "posts"} {
+		for _, association := range buildAssociations(table, nil, foreignKeys) {
+			tags[association.Name] = strings.Join(association.GormOptions, ";")
+		}
+	}
+	want := map[string]string{
+		"Posts": "foreignKey:UserId;references:Id;constraint:OnUpdate:CASCADE,OnDelete:SET NULL",
+		"User":  "foreignKey:UserId;references:Id",
+	}
+	for name, tag := range want {
+		if tags[name] != tag {
+			t.Errorf("%s tag = %q, want %q", name, tags[name], tag)
+		}
+	}
+
+	cache := &sync.Map{}
+	user, err := schema.Parse(&relationUser{}, cache, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	post, err := schema.Parse(&relationPost{}, cache, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	constraint := user.Relationships.Relations["Posts"].ParseConstraint()
+	if constraint == nil {
+		t.Fatal("Posts has no constraint")
+	}
+	if constraint.OnUpdate != "CASCADE" || constraint.OnDelete != "SET NULL" {
+		t.Errorf("Posts constraint = OnUpdate:%s,OnDelete:%s, want OnUpdate:CASCADE,OnDelete:SET NULL", constraint.OnUpdate, constraint.OnDelete)
+	}
+	// The belongs-to side defers to the has-many side, so the foreign key is
+	// created once
+	if constraint := post.Relationships.Relations["User"].ParseConstraint(); constraint != nil {
+		t.Errorf("User has constraint %s, want none", constraint.Name)
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Report summarizes a generation run.
+type Report struct {
+	// DryRun is set when no file was changed, and the files are those the
+	// run would have written, updated or removed
+	DryRun  bool `json:"dryRun"`
+	Tables  int  `json:"tables"`
+	Columns int  `json:"columns"`
+	// Skipped lists the tables incremental generation did not regenerate
+	Skipped   []string   `json:"skipped"`
+	Fallbacks []Fallback `json:"fallbacks"`
+	Samples   []Sample   `json:"samples"`
+	Warnings  []string   `json:"warnings"`
+	// Drift lists the differences the drift command found between the
+	// models and the schema
+	Drift     []string `json:"drift"`
+	Written   []string `json:"written"`
+	Updated   []string `json:"updated"`
+	Unchanged []string `json:"unchanged"`
+	// Removed lists the files of dropped tables removed by -clean
+	Removed []string `json:"removed"`
+	// Merged lists the files edited since they were generated, which the
+	// changes of the run were merged into, and Conflicts those left with
+	// conflict markers
+	Merged    []string `json:"merged"`
+	Conflicts []string `json:"conflicts"`
+	// Files records every file in the order it was handled, for -output
+	// json
+	Files []FileRecord `json:"-"`
+
+	// table is the table being generated, which files and warnings are
+	// recorded for
+	table         string
+	tableWarnings map[string][]string
+}
+
+// FileRecord is a file handled by the run, written by -output json as a
+// line of JSON. Warnings are those of its table, or those of no table for
+// the files shared by all models.
+type FileRecord struct {
+	Table    string   `json:"table"`
+	Path     string   `json:"path"`
+	Action   string   `json:"action"`
+	Warnings []string `json:"warnings"`
+}
+
+// Fallback is a column whose database type is unknown to the generator and
+// was mapped to string.
+type Fallback struct {
+	Table        string `json:"table"`
+	Column       string `json:"column"`
+	DatabaseType string `json:"databaseType"`
+}
+
+// File statuses returned by writeTemplate.
+const (
+	fileWritten   = "written"
+	fileUpdated   = "updated"
+	fileUnchanged = "unchanged"
+	// fileMerged and fileConflicted are edited files that were merged
+	fileMerged     = "merged"
+	fileConflicted = "conflicted"
+	// fileSkipped and fileRemoved are only recorded in Files
+	fileSkipped = "skipped"
+	fileRemoved = "removed"
+)
+
+// warnf records a warning of the table being generated, if any.
+func (r *Report) warnf(format string, args ...interface{}) {
+	r.tableWarnf(r.table, format, args...)
+}
+
+// tableWarnf records a warning of table, or of no table if it is "".
+func (r *Report) tableWarnf(table, format string, args ...interface{}) {
+	warning := fmt.Sprintf(format, args...)
+	r.Warnings = append(r.Warnings, warning)
+	if r.tableWarnings == nil {
+		r.tableWarnings = map[string][]string{}
+	}
+	r.tableWarnings[table] = append(r.tableWarnings[table], warning)
+}
+
+func (r *Report) driftf(format string, args ...interface{}) {
+	r.Drift = append(r.Drift, fmt.Sprintf(format, args...))
+}
+
+// addFile records a file of the table being generated, if any.
+func (r *Report) addFile(path, status string) {
+	r.addTableFile(r.table, path, status)
+}
+
+// addTableFile records a file generated for table, or for no table if it is
+// "".
+func (r *Report) addTableFile(table, path, status string) {
+	switch status {
+	case fileWritten:
+		r.Written = append(r.Written, path)
+	case fileUpdated:
+		r.Updated = append(r.Updated, path)
+	case fileMerged:
+		r.Merged = append(r.Merged, path)
+	case fileConflicted:
+		r.Conflicts = append(r.Conflicts, path)
+	case fileSkipped:
+	case fileRemoved:
+		r.Removed = append(r.Removed, path)
+	default:
+		r.Unchanged = append(r.Unchanged, path)
+	}
+	r.Files = append(r.Files, FileRecord{Table: table, Path: path, Action: status})
+}
+
+// Print writes the report as a summary table followed by the fallback
+// mappings and warnings, if any.
+func (r *Report) Print(w io.Writer) {
+	if r.DryRun {
+		fmt.Fprintln(w, paint(colorBold, "Dry run, no files were changed"))
+	}
+	// Only the counts are colored, as the escape sequences would throw off
+	// the alignment of the labels
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Tables processed\t%d\n", r.Tables)
+	fmt.Fprintf(tw, "Columns mapped\t%d\n", r.Columns)
+	if len(r.Skipped) > 0 {
+		fmt.Fprintf(tw, "Tables unchanged\t%s\n", paintCount(colorDim, len(r.Skipped)))
+	}
+	fmt.Fprintf(tw, "Fallback to string\t%s\n", paintCount(colorYellow, len(r.Fallbacks)))
+	if len(r.Samples) > 0 {
+		fmt.Fprintf(tw, "Sampled types\t%s\n", paintCount(colorCyan, len(r.Samples)))
+	}
+	fmt.Fprintf(tw, "Warnings\t%s\n", paintCount(colorYellow, len(r.Warnings)))
+	if len(r.Drift) > 0 {
+		fmt.Fprintf(tw, "Drift\t%s\n", paintCount(colorRed, len(r.Drift)))
+	}
+	fmt.Fprintf(tw, "Files written\t%s\n", paintCount(colorGreen, len(r.Written)))
+	fmt.Fprintf(tw, "Files updated\t%s\n", paintCount(colorYellow, len(r.Updated)))
+	fmt.Fprintf(tw, "Files unchanged\t%s\n", paintCount(colorDim, len(r.Unchanged)))
+	if len(r.Removed) > 0 {
+		fmt.Fprintf(tw, "Files removed\t%s\n", paintCount(colorRed, len(r.Removed)))
+	}
+	if len(r.Merged) > 0 {
+		fmt.Fprintf(tw, "Files merged\t%s\n", paintCount(colorCyan, len(r.Merged)))
+	}
+	if len(r.Conflicts) > 0 {
+		fmt.Fprintf(tw, "Files with conflicts\t%s\n", paintCount(colorRed, len(r.Conflicts)))
+	}
+	tw.Flush()
+
+	for _, fallback := range r.Fallbacks {
+		fmt.Fprintf(w, "%s %s.%s has unknown type %s, mapped to string; override it with \"columnTypes\": {\"%s.%s\": \"<Go type>\"} in the config file\n", paint(colorYellow, "fallback:"), fallback.Table, fallback.Column, fallback.DatabaseType, fallback.Table, fallback.Column)
+	}
+	for _, sample := range r.Samples {
+		action := "suggested"
+		if sample.Applied {
+			action = "applied"
+		}
+		fmt.Fprintf(w, "%s %s.%s holds %s in all %d sampled values, %s %s\n", paint(colorCyan, "sampled:"), sample.Table, sample.Column, sample.Holds, sample.Values, sample.GoType, action)
+	}
+	for _, warning := range r.Warnings {
+		fmt.Fprintf(w, "%s %s\n", paint(colorYellow, "warning:"), warning)
+	}
+	for _, drift := range r.Drift {
+		fmt.Fprintf(w, "%s %s\n", paint(colorRed, "drift:"), drift)
+	}
+	for _, conflict := range r.Conflicts {
+		fmt.Fprintf(w, "%s %s was edited and has conflict markers to resolve\n", paint(colorRed, "conflict:"), conflict)
+	}
+}
+
+// paintCount returns a count of the summary, in color unless it is 0.
+func paintCount(color string, count int) string {
+	if count == 0 {
+		return "0"
+	}
+	return paint(color, fmt.Sprint(count))
+}
+
+// PrintJSON writes the report as indented JSON. Empty lists are written as
+// [] rather than null.
+func (r *Report) PrintJSON(w io.Writer) error {
+	report := *r
+	for _, list := range []*[]string{&report.Skipped, &report.Warnings, &report.Drift, &report.Written, &report.Updated, &report.Unchanged, &report.Removed, &report.Merged, &report.Conflicts} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+	if report.Fallbacks == nil {
+		report.Fallbacks = []Fallback{}
+	}
+	if report.Samples == nil {
+		report.Samples = []Sample{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// PrintFiles writes a line of JSON per file, with the warnings of its table.
+func (r *Report) PrintFiles(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	for _, file := range r.Files {
+		file.Warnings = r.tableWarnings[file.Table]
+		if file.Warnings == nil {
+			file.Warnings = []string{}
+		}
+		if err := encoder.Encode(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
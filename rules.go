@@ -0,0 +1,103 @@
+package main
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// TagRule adds struct tags and gorm options to the columns it matches, e.g.
+// json:"-" on every *_token column. A rule matches a column when every
+// condition it sets holds; a rule without conditions matches every column.
+type TagRule struct {
+	// Columns are "table.column" or "column" patterns, as in Sensitive
+	Columns []string `json:"columns"`
+	// DatabaseTypes are patterns on the database type, with or without its
+	// parameters, e.g. "json" or "varchar(255)"
+	DatabaseTypes []string `json:"databaseTypes"`
+	// GoTypes are patterns on the generated Go type, e.g. "*time.Time"
+	GoTypes    []string `json:"goTypes"`
+	Nullable   *bool    `json:"nullable"`
+	PrimaryKey *bool    `json:"primaryKey"`
+	// Tags are set on matching columns, replacing tags of the same key.
+	// "{column}" in a value is replaced with the column name.
+	Tags map[string]string `json:"tags"`
+	// GormOptions are appended to the gorm tag, e.g. "serializer:json"
+	GormOptions []string `json:"gormOptions"`
+}
+
+// matches reports whether the rule applies to a column of a table.
+func (rule TagRule) matches(tableName string, column Column) bool {
+	if len(rule.Columns) > 0 && !matchColumn(rule.Columns, tableName, column.GormName) {
+		return false
+	}
+	baseType, _, _ := strings.Cut(column.DatabaseType, "(")
+	if len(rule.DatabaseTypes) > 0 && !matchAny(rule.DatabaseTypes, column.DatabaseType) && !matchAny(rule.DatabaseTypes, baseType) {
+		return false
+	}
+	if len(rule.GoTypes) > 0 && !matchAny(rule.GoTypes, column.Type) {
+		return false
+	}
+	if rule.Nullable != nil && *rule.Nullable != column.Nullable {
+		return false
+	}
+	if rule.PrimaryKey != nil && *rule.PrimaryKey != column.PrimaryKey {
+		return false
+	}
+	return true
+}
+
+// applyTagRules applies the matching rules to a column in order, so later
+// rules override the tags set by earlier ones.
+func applyTagRules(rules []TagRule, tableName string, column *Column) {
+	for _, rule := range rules {
+		if !rule.matches(tableName, *column) {
+			continue
+		}
+
+		keys := make([]string, 0, len(rule.Tags))
+		for key := range rule.Tags {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := strings.ReplaceAll(rule.Tags[key], "{column}", column.GormName)
+			column.Tags = setTag(column.Tags, key, value)
+		}
+
+		for _, option := range rule.GormOptions {
+			if !containsString(column.GormOptions, option) {
+				column.GormOptions = append(column.GormOptions, option)
+			}
+		}
+	}
+}
+
+// setTag sets the value of a tag, replacing an existing tag with the same key.
+func setTag(tags []Tag, key, value string) []Tag {
+	for i := range tags {
+		if tags[i].Key == key {
+			tags[i].Value = value
+			return tags
+		}
+	}
+	return append(tags, Tag{Key: key, Value: value})
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
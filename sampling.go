@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Sample is a text column whose sampled values all hold a more specific
+// type, and the Go type suggested, or applied, for it.
+type Sample struct {
+	Table   string `json:"table"`
+	Column  string `json:"column"`
+	Holds   string `json:"holds"`
+	GoType  string `json:"goType"`
+	Values  int    `json:"values"`
+	Applied bool   `json:"applied"`
+}
+
+// sampleValues returns the non-NULL values of up to limit rows of a table,
+// by column.
+func sampleValues(db *gorm.DB, tableName string, limit int) (map[string][]string, error) {
+	rows, err := db.Table(tableName).Limit(limit).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	samples := map[string][]string{}
+	for rows.Next() {
+		values := make([]interface{}, len(names))
+		targets := make([]interface{}, len(names))
+		for i := range values {
+			targets[i] = &values[i]
+		}
+		if err := rows.Scan(targets...); err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			switch v := value.(type) {
+			case []byte:
+				samples[names[i]] = append(samples[names[i]], string(v))
+			case string:
+				samples[names[i]] = append(samples[names[i]], v)
+			}
+		}
+	}
+	return samples, rows.Err()
+}
+
+// textColumn reports whether a database type holds text, e.g. varchar or
+// text, as opposed to other types mapped to string such as decimal.
+func textColumn(databaseType string) bool {
+	databaseType = strings.ToLower(databaseType)
+	return strings.Contains(databaseType, "char") || strings.Contains(databaseType, "text") || databaseType == "string"
+}
+
+// sampledType returns the Go type every sampled value of a text column
+// converts to, the import it requires and what the values hold: UUIDs, JSON
+// objects or arrays, or booleans. ok is false when there are no values or
+// they are plain text.
+func sampledType(values []string) (goType, importPath, holds string, ok bool) {
+	if len(values) == 0 {
+		return "", "", "", false
+	}
+	all := func(match func(string) bool) bool {
+		for _, value := range values {
+			if !match(value) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch {
+	case all(uuidPattern.MatchString):
+		return "uuid.UUID", "github.com/google/uuid", "UUIDs", true
+	case all(func(value string) bool {
+		value = strings.TrimSpace(value)
+		return (strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[")) && json.Valid([]byte(value))
+	}):
+		return "json.RawMessage", "encoding/json", "JSON", true
+	case all(func(value string) bool {
+		_, err := strconv.ParseBool(value)
+		return err == nil
+	}):
+		return "bool", "", "booleans", true
+	}
+	return "", "", "", false
+}
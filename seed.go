@@ -0,0 +1,332 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var seedTemplate = `package models
+
+import (
+{{- range $i, $group := .Imports }}
+{{- if $i }}
+{{ end }}
+{{- range $group }}
+    "{{.}}"
+{{- end }}
+{{- end }}
+)
+
+// Seed inserts the rows exported by the seed command, referenced tables
+// first.{{if .OnConflict}} Rows that already exist are skipped, so it can be run repeatedly.{{end}}
+func Seed(db *gorm.DB) error {
+    return db.Transaction(func(tx *gorm.DB) error {
+{{- range .Tables }}
+        if err := tx{{if $.OnConflict}}.Clauses(clause.OnConflict{DoNothing: true}){{end}}.Create(&[]{{.Model}}{
+{{- range .Rows }}
+            { {{.}} },
+{{- end }}
+        }).Error; err != nil {
+            return err
+        }
+{{- end }}
+        return nil
+    })
+}
+{{- if .Pointers }}
+
+func seedPtr[T any](value T) *T {
+    return &value
+}
+{{- end }}
+`
+
+// SeedConfig selects the rows of a table exported by the seed command.
+type SeedConfig struct {
+	Where string `json:"where"`
+	Limit int    `json:"limit"`
+}
+
+// Seed is the data of the generated seed.go file.
+type Seed struct {
+	Imports    [][]string
+	OnConflict bool
+	Pointers   bool
+	Tables     []SeedTable
+}
+
+// SeedTable holds the rows of a table as composite literal fields, e.g.
+// Id: 1, Name: "Germany".
+type SeedTable struct {
+	Model string
+	Rows  []string
+}
+
+// seed exports rows of the given tables to seed.go in destPath, as a Seed
+// function creating them with the generated models.
+func seed(db *gorm.DB, driver string, tableNames []string, destPath string, limit int, config Config, report *Report) {
+	allForeignKeys, err := loadForeignKeys(db, driver)
+	if err != nil {
+		log.Fatalf("Failed to get foreign keys: %s", explain(err))
+	}
+	requested := map[string]bool{}
+	for _, tableName := range tableNames {
+		requested[tableName] = true
+	}
+	var foreignKeys []ForeignKey
+	for _, fk := range allForeignKeys {
+		if requested[fk.Table] && requested[fk.ReferencedTable] {
+			foreignKeys = append(foreignKeys, fk)
+		}
+	}
+
+	var tables []Table
+	for _, tableName := range tableNames {
+		tables = append(tables, buildModel(db, driver, tableName, nil, "", config, report))
+	}
+	// Insert referenced rows first so foreign key constraints hold
+	order, err := orderModels(tables, foreignKeys)
+	if err != nil {
+		log.Fatalf("Failed to order tables to seed: %v", err)
+	}
+
+	data := Seed{OnConflict: driver != "clickhouse"}
+	imports := map[string]bool{"gorm.io/gorm": true}
+	if data.OnConflict {
+		imports["gorm.io/gorm/clause"] = true
+	}
+	for _, model := range order {
+		for _, table := range tables {
+			if table.TableName != model {
+				continue
+			}
+			seedTable, tableImports, pointers := seedRows(db, table, limit, config.Seed[table.DBTableName], report)
+			if len(seedTable.Rows) == 0 {
+				continue
+			}
+			data.Tables = append(data.Tables, seedTable)
+			data.Pointers = data.Pointers || pointers
+			for _, importPath := range tableImports {
+				imports[importPath] = true
+			}
+		}
+	}
+	var importPaths []string
+	for importPath := range imports {
+		importPaths = append(importPaths, importPath)
+	}
+	sort.Strings(importPaths)
+	data.Imports = Table{ModelImports: importPaths}.ImportGroups()
+
+	path := fmt.Sprintf("%s/seed.go", destPath)
+	report.addFile(path, writeTemplate(seedTemplate, path, data))
+}
+
+// seedRows selects the rows of a table, in primary key order, and returns
+// them as composite literals of its model, the imports they require and
+// whether they use seedPtr. Columns whose type cannot be written are left
+// out and reported.
+func seedRows(db *gorm.DB, table Table, limit int, seedConfig SeedConfig, report *Report) (SeedTable, []string, bool) {
+	query := db.Table(table.DBTableName)
+	if seedConfig.Where != "" {
+		query = query.Where(seedConfig.Where)
+	}
+	if seedConfig.Limit > 0 {
+		limit = seedConfig.Limit
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	for _, column := range table.Columns {
+		if column.PrimaryKey {
+			query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: column.GormName}})
+		}
+	}
+	rows, err := query.Rows()
+	if err != nil {
+		log.Fatalf("Failed to select rows of table %s: %v", table.DBTableName, err)
+	}
+	defer rows.Close()
+	names, err := rows.Columns()
+	if err != nil {
+		log.Fatalf("Failed to select rows of table %s: %v", table.DBTableName, err)
+	}
+
+	enumTypes := map[string]bool{}
+	for _, enum := range table.Enums {
+		enumTypes[enum.Name] = true
+	}
+	seedTable := SeedTable{Model: table.TableName}
+	var imports []string
+	pointers := false
+	skipped := map[string]bool{}
+	for rows.Next() {
+		values := make([]interface{}, len(names))
+		targets := make([]interface{}, len(names))
+		for i := range values {
+			targets[i] = &values[i]
+		}
+		if err := rows.Scan(targets...); err != nil {
+			log.Fatalf("Failed to read row of table %s: %v", table.DBTableName, err)
+		}
+		row := map[string]interface{}{}
+		for i, name := range names {
+			row[name] = values[i]
+		}
+
+		var fields []string
+		for _, column := range table.Columns {
+			value := row[column.GormName]
+			if column.Ignored || value == nil {
+				continue
+			}
+			expr, importPath, ok := seedValue(column.Type, value, enumTypes)
+			if !ok {
+				if !skipped[column.GormName] {
+					report.warnf("%s.%s of type %s is not seeded", table.DBTableName, column.GormName, column.Type)
+					skipped[column.GormName] = true
+				}
+				continue
+			}
+			if importPath != "" && !containsString(imports, importPath) {
+				imports = append(imports, importPath)
+			}
+			// Null wrappers of times need the time package too
+			if strings.Contains(expr, "time.Date(") && !containsString(imports, "time") {
+				imports = append(imports, "time")
+			}
+			pointers = pointers || strings.HasPrefix(expr, "seedPtr[")
+			fields = append(fields, fmt.Sprintf("%s: %s", column.Name, expr))
+		}
+		for _, money := range table.MoneyFields {
+			var parts []string
+			for _, part := range []struct{ field, column string }{{"Amount", "amount"}, {"Currency", "currency"}} {
+				if value := row[money.Prefix+part.column]; value != nil {
+					parts = append(parts, fmt.Sprintf("%s: %s", part.field, strconv.Quote(seedText(value))))
+				}
+			}
+			if len(parts) > 0 {
+				fields = append(fields, fmt.Sprintf("%s: Money{%s}", money.Name, strings.Join(parts, ", ")))
+			}
+		}
+		seedTable.Rows = append(seedTable.Rows, strings.Join(fields, ", "))
+	}
+	if err := rows.Err(); err != nil {
+		log.Fatalf("Failed to read rows of table %s: %v", table.DBTableName, err)
+	}
+	return seedTable, imports, pointers
+}
+
+// seedValue returns the Go expression of a value read from a column whose
+// field has goType, and the import it requires, if any. ok is false for types
+// the seed command cannot write, such as encrypted or custom types.
+func seedValue(goType string, value interface{}, enumTypes map[string]bool) (string, string, bool) {
+	if strings.HasPrefix(goType, "*") {
+		elemType := strings.TrimPrefix(goType, "*")
+		expr, importPath, ok := seedValue(elemType, value, enumTypes)
+		return fmt.Sprintf("seedPtr[%s](%s)", elemType, expr), importPath, ok
+	}
+	if enumTypes[goType] {
+		return fmt.Sprintf("%s(%s)", goType, strconv.Quote(seedText(value))), "", true
+	}
+
+	nullTypes := map[string]struct{ field, valueType string }{
+		"sql.NullString":  {"String", "string"},
+		"sql.NullInt64":   {"Int64", "int64"},
+		"sql.NullInt32":   {"Int32", "int32"},
+		"sql.NullInt16":   {"Int16", "int16"},
+		"sql.NullFloat64": {"Float64", "float64"},
+		"sql.NullBool":    {"Bool", "bool"},
+		"sql.NullTime":    {"Time", "time.Time"},
+	}
+	if null, ok := nullTypes[goType]; ok {
+		expr, _, ok := seedValue(null.valueType, value, enumTypes)
+		return fmt.Sprintf("%s{%s: %s, Valid: true}", goType, null.field, expr), "database/sql", ok
+	}
+	if goType == "gorm.DeletedAt" {
+		expr, _, ok := seedValue("time.Time", value, enumTypes)
+		return fmt.Sprintf("gorm.DeletedAt{Time: %s, Valid: true}", expr), "gorm.io/gorm", ok
+	}
+	gureguTypes := map[string]string{
+		"null.String": "string",
+		"null.Int":    "int64",
+		"null.Float":  "float64",
+		"null.Bool":   "bool",
+		"null.Time":   "time.Time",
+	}
+	if valueType, ok := gureguTypes[goType]; ok {
+		expr, _, ok := seedValue(valueType, value, enumTypes)
+		return fmt.Sprintf("%sFrom(%s)", goType, expr), gureguNullImport, ok
+	}
+
+	text := seedText(value)
+	switch goType {
+	case "string":
+		return strconv.Quote(text), "", true
+	case "[]byte", "[]uint8":
+		return fmt.Sprintf("[]byte(%s)", strconv.Quote(text)), "", true
+	case "json.RawMessage":
+		return fmt.Sprintf("json.RawMessage(%s)", strconv.Quote(text)), "encoding/json", true
+	case "bool":
+		switch strings.ToLower(text) {
+		case "1", "true", "t":
+			return "true", "", true
+		case "0", "false", "f":
+			return "false", "", true
+		}
+		return "", "", false
+	case "int", "int8", "int16", "int32", "int64":
+		_, err := strconv.ParseInt(text, 10, 64)
+		return text, "", err == nil
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		_, err := strconv.ParseUint(text, 10, 64)
+		return text, "", err == nil
+	case "float32", "float64":
+		_, err := strconv.ParseFloat(text, 64)
+		return text, "", err == nil
+	case "uuid.UUID":
+		return fmt.Sprintf("uuid.MustParse(%s)", strconv.Quote(text)), "github.com/google/uuid", true
+	case "netip.Addr":
+		return fmt.Sprintf("netip.MustParseAddr(%s)", strconv.Quote(text)), "net/netip", true
+	case "time.Time":
+		t, ok := value.(time.Time)
+		if !ok {
+			var err error
+			if t, err = time.Parse("2006-01-02 15:04:05.999999999", text); err != nil {
+				if t, err = time.Parse("2006-01-02", text); err != nil {
+					return "", "", false
+				}
+			}
+		}
+		t = t.UTC()
+		return fmt.Sprintf("time.Date(%d, %d, %d, %d, %d, %d, %d, time.UTC)", t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond()), "time", true
+	}
+	return "", "", false
+}
+
+// seedText returns the text of a value read from the database.
+func seedText(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05.999999999")
+	default:
+		return fmt.Sprint(v)
+	}
+}
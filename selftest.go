@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+	tcmysql "github.com/testcontainers/testcontainers-go/modules/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// runSelftest checks the generator against a MySQL server: it starts a
+// disposable container from image with testcontainers, applies schemaFile,
+// generates models for every table and compiles them in a scratch module.
+// Generation settings come from config, so the configuration in use is
+// tested too.
+func runSelftest(schemaFile, image string, config Config) error {
+	schemaFile, err := filepath.Abs(schemaFile)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	container, err := tcmysql.RunContainer(ctx,
+		testcontainers.WithImage(image),
+		tcmysql.WithDatabase("selftest"),
+		tcmysql.WithUsername("selftest"),
+		tcmysql.WithPassword("selftest"),
+		tcmysql.WithScripts(schemaFile),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to start %s: %w", image, err)
+	}
+	defer container.Terminate(ctx)
+
+	var params []string
+	if timeParams := timeDSNParams("mysql", config.TimeMode); timeParams != "" {
+		params = append(params, timeParams)
+	}
+	dsn, err := container.ConnectionString(ctx, params...)
+	if err != nil {
+		return err
+	}
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	var tableNames []string
+	err = db.Raw("SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_NAME").Scan(&tableNames).Error
+	if err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+	if len(tableNames) == 0 {
+		return fmt.Errorf("%s creates no tables", schemaFile)
+	}
+
+	dir, err := os.MkdirTemp("", "gorm-models-selftest")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	modelsDir := filepath.Join(dir, "models")
+	if err := os.Mkdir(modelsDir, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module selftest\n\ngo 1.21\n"), 0o644); err != nil {
+		return err
+	}
+
+	var report Report
+	generate(db, "mysql", tableNames, modelsDir, Lock{}, config, &report)
+	report.Print(os.Stdout)
+
+	// Resolve the imports of the generated models, then compile them and
+	// any generated tests
+	for _, args := range [][]string{{"mod", "tidy"}, {"vet", "./..."}} {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = dir
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("go %s failed on the generated models: %w", strings.Join(args, " "), err)
+		}
+	}
+
+	fmt.Printf("Self-test passed: generated and compiled %d models with %s\n", len(tableNames), image)
+	return nil
+}
@@ -0,0 +1,80 @@
+//go:build integration
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// selftestSchema covers the column types, keys and associations the
+// generator maps for MySQL.
+const selftestSchema = `CREATE TABLE users (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    name VARCHAR(100) NULL,
+    status ENUM('active', 'disabled') NOT NULL DEFAULT 'active',
+    balance DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    settings JSON NULL,
+    avatar BLOB NULL,
+    created_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at DATETIME NULL,
+    UNIQUE KEY uk_users_email (email),
+    KEY idx_users_deleted_at (deleted_at)
+);
+
+CREATE TABLE orders (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL,
+    amount DECIMAL(12, 2) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    note TEXT NULL,
+    placed_on DATE NOT NULL,
+    CONSTRAINT fk_orders_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE VIEW active_users AS SELECT id, email FROM users WHERE status = 'active';
+`
+
+// TestSelftest runs -selftest against a MySQL container, which needs Docker
+// and the Go toolchain. Run it with go test -tags integration.
+func TestSelftest(t *testing.T) {
+	if testing.Short() {
+		t.Skip("starts a MySQL container")
+	}
+	schemaFile := filepath.Join(t.TempDir(), "schema.sql")
+	if err := os.WriteFile(schemaFile, []byte(selftestSchema), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	image := os.Getenv("SELFTEST_IMAGE")
+	if image == "" {
+		image = "mysql:8.0"
+	}
+	configs := map[string]Config{
+		"defaults": {TimeMode: "utc", NullStyle: "pointer", ConstructorStyle: "required"},
+		"features": {
+			TimeMode:         "utc",
+			NullStyle:        "sqlnull",
+			ConstructorStyle: "options",
+			SoftDelete:       true,
+			QueryBuilders:    true,
+			Filters:          true,
+			Pagination:       true,
+			Constructors:     true,
+			Builders:         true,
+			Getters:          true,
+			CloneEqual:       true,
+			Stringer:         true,
+		},
+	}
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			if err := runSelftest(schemaFile, image, config); err != nil {
+				t.Fatalf("self-test failed: %v", err)
+			}
+		})
+	}
+}
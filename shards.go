@@ -0,0 +1,131 @@
+package main
+
+import (
+	"path"
+	"strings"
+)
+
+var shardTemplate = `package models
+
+import (
+    "fmt"
+    "path"
+    "unicode"
+
+    "gorm.io/gorm"
+)
+
+// TableNameFor returns the name of the shard of {{.TableName}} with a key, e.g.
+// {{printf "%q" .Example}} for {{.DBTableName}}. Keys with other characters than letters,
+// digits and underscores, or naming no table matching {{.Pattern}}, are
+// rejected, so the name is safe to query.
+func ({{.TableName}}) TableNameFor(shardKey string) (string, error) {
+    name := {{printf "%q" .Prefix}} + shardKey
+    matched, _ := path.Match({{printf "%q" .Pattern}}, name)
+    for _, c := range shardKey {
+        matched = matched && (unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_')
+    }
+    if !matched {
+        return "", fmt.Errorf("%q is not a shard key of {{.Family}}", shardKey)
+    }
+    return name, nil
+}
+
+// InShard scopes a query to the shard of {{.TableName}} with a key, e.g.
+// db.Scopes({{.TableName}}{}.InShard({{printf "%q" .Example}})).Find(&rows). The query fails if
+// the key is rejected by TableNameFor.
+func (m {{.TableName}}) InShard(shardKey string) func(*gorm.DB) *gorm.DB {
+    return func(db *gorm.DB) *gorm.DB {
+        name, err := m.TableNameFor(shardKey)
+        if err != nil {
+            db.AddError(err)
+            return db
+        }
+        return db.Table(name)
+    }
+}
+`
+
+// ShardHelpers is the data of a generated <Model>Shard.go file.
+type ShardHelpers struct {
+	TableName   string
+	DBTableName string
+	Family      string
+	Pattern     string
+	// Prefix is the start of the shard names the key is appended to
+	Prefix string
+	// Example is the key of the shard the model is generated from
+	Example string
+}
+
+// shardFamily returns the family of a sharded table and the pattern of
+// ShardPatterns it matches, e.g. events for events_2023_01 and events_*.
+func shardFamily(patterns []string, tableName string) (string, string, bool) {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, tableName); matched {
+			return shardFamilyName(pattern), pattern, true
+		}
+	}
+	return "", "", false
+}
+
+// shardFamilyName returns the table name a shard pattern starts with, before
+// its first wildcard and separator, e.g. events for events_*.
+func shardFamilyName(pattern string) string {
+	return strings.TrimRight(shardPrefix(pattern), "_-")
+}
+
+// shardPrefix returns the literal start of a shard pattern, before its first
+// wildcard, e.g. events_ for events_*.
+func shardPrefix(pattern string) string {
+	if i := strings.IndexAny(pattern, "*?[\\"); i >= 0 {
+		return pattern[:i]
+	}
+	return pattern
+}
+
+// validShardPattern reports whether a shard pattern is well-formed, starts
+// with a table name and has a wildcard matching the shards.
+func validShardPattern(pattern string) bool {
+	_, err := path.Match(pattern, "")
+	return err == nil && isTablePattern(pattern) && shardFamilyName(pattern) != ""
+}
+
+// collapseShards keeps one table per shard family among tableNames, the last
+// by name, e.g. events_2023_02 of events_2023_01 and events_2023_02, whose
+// model stands for the family.
+func collapseShards(patterns, tableNames []string) []string {
+	last := map[string]string{}
+	for _, tableName := range tableNames {
+		if family, _, ok := shardFamily(patterns, tableName); ok && tableName > last[family] {
+			last[family] = tableName
+		}
+	}
+	var kept []string
+	for _, tableName := range tableNames {
+		if family, _, ok := shardFamily(patterns, tableName); ok && last[family] != tableName {
+			continue
+		}
+		kept = append(kept, tableName)
+	}
+	return kept
+}
+
+// buildShardHelpers derives the shard helpers of the model of a sharded
+// family. ok is false when a field is named like one of them.
+func buildShardHelpers(table Table) (ShardHelpers, bool) {
+	for _, field := range accessorFields(table) {
+		if field.Name == "TableNameFor" || field.Name == "InShard" {
+			return ShardHelpers{}, false
+		}
+	}
+	prefix := shardPrefix(table.ShardPattern)
+	return ShardHelpers{
+		TableName:   table.TableName,
+		DBTableName: table.DBTableName,
+		Family:      shardFamilyName(table.ShardPattern),
+		Pattern:     table.ShardPattern,
+		Prefix:      prefix,
+		Example:     strings.TrimPrefix(table.DBTableName, prefix),
+	}, true
+}
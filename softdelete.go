@@ -0,0 +1,83 @@
+package main
+
+import "strings"
+
+var softDeleteTemplate = `package models
+
+import (
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// WithDeleted includes soft-deleted rows in a query, e.g.
+// db.Scopes(models.WithDeleted()).Find(&users).
+func WithDeleted() func(*gorm.DB) *gorm.DB {
+    return func(db *gorm.DB) *gorm.DB {
+        return db.Unscoped()
+    }
+}
+
+// OnlyDeleted restricts a query to soft-deleted rows, e.g. to list the rows
+// that can be restored.
+func OnlyDeleted() func(*gorm.DB) *gorm.DB {
+    return func(db *gorm.DB) *gorm.DB {
+        return db.Unscoped().Where(clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: "{{.}}"}, Value: nil})
+    }
+}
+`
+
+// softDeleteColumnName returns the column holding soft deletes.
+func softDeleteColumnName(config Config) string {
+	if config.SoftDeleteColumn != "" {
+		return config.SoftDeleteColumn
+	}
+	return "deleted_at"
+}
+
+// softDeleteType reports whether a column whose field has goType can be
+// mapped to gorm.DeletedAt, which is a nullable time.
+func softDeleteType(goType string) bool {
+	switch goType {
+	case "*time.Time", "sql.NullTime", "null.Time":
+		return true
+	}
+	return false
+}
+
+// softDeleteColumn returns the soft delete column of a table, if it has one.
+func softDeleteColumn(config Config, table Table) (Column, bool) {
+	for _, column := range table.Columns {
+		if column.GormName == softDeleteColumnName(config) && strings.HasSuffix(column.Type, "gorm.DeletedAt") && !column.Ignored {
+			return column, true
+		}
+	}
+	return Column{}, false
+}
+
+// softDeleteIndexes adds the indexes covering the soft delete column to the
+// gorm tags of their columns, so AutoMigrate creates them too, unless they
+// are tagged already as taggedIndex. ok is false when no index covers the
+// column.
+func softDeleteIndexes(columns []Column, indexes []Index, columnName string) bool {
+	found := false
+	for _, index := range indexes {
+		if index.PrimaryKey || !containsString(index.Columns, columnName) {
+			continue
+		}
+		found = true
+		if !taggedIndex(index) {
+			indexTags(columns, index)
+		}
+	}
+	return found
+}
+
+// usesSoftDelete reports whether any generated model has soft deletes.
+func usesSoftDelete(config Config, tables []Table) bool {
+	for _, table := range tables {
+		if _, ok := softDeleteColumn(config, table); ok {
+			return true
+		}
+	}
+	return false
+}
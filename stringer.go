@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+)
+
+// identifyingColumns are used by String() when no columns are configured.
+var identifyingColumns = []string{"name", "title", "email", "username", "slug", "code"}
+
+// StringField is a field printed by a generated String method.
+type StringField struct {
+	Name string
+	Verb string
+}
+
+// stringFields selects the fields printed by the String method of a table: the
+// primary key followed by the configured columns, or by the first identifying
+// column when none are configured. Sensitive and encrypted columns are never
+// printed, and pointer, slice and map fields are skipped since they do not
+// print usefully.
+func stringFields(config Config, tableName string, columns []Column) []StringField {
+	wanted, configured := config.StringColumns[tableName]
+	if !configured {
+		for _, name := range identifyingColumns {
+			if hasColumn(columns, name) {
+				wanted = []string{name}
+				break
+			}
+		}
+	}
+
+	var fields []StringField
+	add := func(column Column) {
+		if matchColumn(config.Sensitive, tableName, column.GormName) || matchColumn(config.Encrypted, tableName, column.GormName) || strings.HasPrefix(column.Type, "*") || strings.HasPrefix(column.Type, "[]") || strings.HasPrefix(column.Type, "map[") {
+			return
+		}
+		for _, field := range fields {
+			if field.Name == column.Name {
+				return
+			}
+		}
+		verb := "%v"
+		if column.Type == "string" {
+			verb = "%q"
+		}
+		fields = append(fields, StringField{Name: column.Name, Verb: verb})
+	}
+
+	for _, column := range columns {
+		if column.PrimaryKey {
+			add(column)
+		}
+	}
+	for _, name := range wanted {
+		for _, column := range columns {
+			if column.GormName == name {
+				add(column)
+			}
+		}
+	}
+	return fields
+}
+
+func hasColumn(columns []Column, name string) bool {
+	for _, column := range columns {
+		if column.GormName == name {
+			return true
+		}
+	}
+	return false
+}
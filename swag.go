@@ -0,0 +1,77 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+var enumValuePattern = regexp.MustCompile(`'((?:[^']|'')*)'`)
+
+// enumValues returns the values of a MySQL enum or set column type, e.g.
+// enum('draft','published') -> [draft published].
+func enumValues(databaseType string) []string {
+	lower := strings.ToLower(databaseType)
+	if !strings.HasPrefix(lower, "enum(") && !strings.HasPrefix(lower, "set(") {
+		return nil
+	}
+	var values []string
+	for _, match := range enumValuePattern.FindAllStringSubmatch(databaseType, -1) {
+		values = append(values, strings.ReplaceAll(match[1], "''", "'"))
+	}
+	return values
+}
+
+// swagTags returns the swaggo attributes of a column: swaggertype for types
+// swag cannot inspect, format, an example value and the allowed enum values.
+func swagTags(column Column) []Tag {
+	var tags []Tag
+	goType := strings.TrimPrefix(column.Type, "*")
+
+	switch goType {
+	case "sql.NullString", "null.String":
+		tags = append(tags, Tag{Key: "swaggertype", Value: "string"})
+	case "sql.NullInt64", "sql.NullInt32", "sql.NullInt16", "null.Int":
+		tags = append(tags, Tag{Key: "swaggertype", Value: "integer"})
+	case "sql.NullFloat64", "null.Float":
+		tags = append(tags, Tag{Key: "swaggertype", Value: "number"})
+	case "sql.NullBool", "null.Bool":
+		tags = append(tags, Tag{Key: "swaggertype", Value: "boolean"})
+	case "sql.NullTime", "null.Time", "gorm.DeletedAt":
+		tags = append(tags, Tag{Key: "swaggertype", Value: "string"})
+		goType = "time.Time"
+	case "json.RawMessage":
+		tags = append(tags, Tag{Key: "swaggertype", Value: "object"})
+	}
+
+	databaseType := strings.ToLower(column.DatabaseType)
+	switch {
+	case strings.HasPrefix(databaseType, "date") && !strings.HasPrefix(databaseType, "datetime"):
+		tags = append(tags, Tag{Key: "format", Value: "date"}, Tag{Key: "example", Value: "2024-01-31"})
+	case goType == "time.Time":
+		tags = append(tags, Tag{Key: "format", Value: "date-time"}, Tag{Key: "example", Value: "2024-01-31T15:04:05Z"})
+	case databaseType == "uuid":
+		tags = append(tags, Tag{Key: "format", Value: "uuid"}, Tag{Key: "example", Value: "550e8400-e29b-41d4-a716-446655440000"})
+	case strings.HasPrefix(goType, "int") || strings.HasPrefix(goType, "uint") || strings.Contains(goType, "NullInt") || goType == "null.Int":
+		tags = append(tags, Tag{Key: "example", Value: "1"})
+	case strings.HasPrefix(goType, "float") || goType == "sql.NullFloat64" || goType == "null.Float":
+		tags = append(tags, Tag{Key: "example", Value: "1.5"})
+	case goType == "bool" || goType == "sql.NullBool" || goType == "null.Bool":
+		tags = append(tags, Tag{Key: "example", Value: "true"})
+	}
+
+	if values := enumValues(column.DatabaseType); len(values) > 0 && !strings.HasPrefix(databaseType, "set(") && taggable(values) {
+		tags = append(tags, Tag{Key: "enums", Value: strings.Join(values, ",")}, Tag{Key: "example", Value: values[0]})
+	}
+	return tags
+}
+
+// taggable reports whether values can be written into a comma-separated
+// struct tag value without escaping.
+func taggable(values []string) bool {
+	for _, value := range values {
+		if strings.ContainsAny(value, ",\"`\\") {
+			return false
+		}
+	}
+	return true
+}
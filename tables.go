@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// readTablesFile returns the tables listed in a file, one per line. Blank
+// lines are skipped, and # starts a comment, on a line of its own or after a
+// table.
+func readTablesFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var tableNames []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if tableName := strings.TrimSpace(line); tableName != "" {
+			tableNames = append(tableNames, tableName)
+		}
+	}
+	return tableNames, scanner.Err()
+}
+
+// isTablePattern reports whether a table of -tables is a path.Match pattern.
+func isTablePattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// expandTables replaces the patterns among tableNames, e.g. billing_*, with
+// the tables of the database they match, in alphabetical order. Tables are
+// kept once, where they first appear.
+func expandTables(tableNames, databaseTables []string) ([]string, error) {
+	sort.Strings(databaseTables)
+	seen := map[string]bool{}
+	var expanded []string
+	for _, name := range tableNames {
+		if !isTablePattern(name) {
+			if !seen[name] {
+				seen[name] = true
+				expanded = append(expanded, name)
+			}
+			continue
+		}
+		if _, err := path.Match(name, ""); err != nil {
+			return nil, fmt.Errorf("invalid table pattern %s: %w", name, err)
+		}
+		matched := false
+		for _, tableName := range databaseTables {
+			if ok, _ := path.Match(name, tableName); ok {
+				matched = true
+				if !seen[tableName] {
+					seen[tableName] = true
+					expanded = append(expanded, tableName)
+				}
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("no table matches %s", name)
+		}
+	}
+	return expanded, nil
+}
+
+// systemSchemas are the schemas of the server itself, by driver
+var systemSchemas = map[string][]string{
+	"mysql":      {"mysql", "sys", "information_schema", "performance_schema"},
+	"tidb":       {"mysql", "sys", "information_schema", "performance_schema", "metrics_schema"},
+	"clickhouse": {"system", "information_schema"},
+}
+
+// frameworkTables are the tables migration tools and extensions keep their
+// state in, in lower case
+var frameworkTables = map[string]bool{
+	"schema_migrations":           true, // Rails, golang-migrate, dbmate
+	"ar_internal_metadata":        true, // Rails
+	"migrations":                  true, // gormigrate, Laravel, TypeORM
+	"flyway_schema_history":       true,
+	"schema_version":              true, // Flyway before 5
+	"databasechangelog":           true, // Liquibase
+	"databasechangeloglock":       true,
+	"goose_db_version":            true,
+	"gorp_migrations":             true, // sql-migrate
+	"atlas_schema_revisions":      true,
+	"_sqlx_migrations":            true,
+	"_prisma_migrations":          true,
+	"__diesel_schema_migrations":  true,
+	"__efmigrationshistory":       true,
+	"django_migrations":           true,
+	"alembic_version":             true,
+	"knex_migrations":             true,
+	"knex_migrations_lock":        true,
+	"sequelizemeta":               true,
+	"doctrine_migration_versions": true,
+	"phinxlog":                    true,
+	"spatial_ref_sys":             true, // PostGIS
+}
+
+// isSystemSchema reports whether database is a schema of the server itself,
+// e.g. performance_schema on MySQL.
+func isSystemSchema(driver, database string) bool {
+	for _, schema := range systemSchemas[driver] {
+		if strings.EqualFold(schema, database) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutFrameworkTables returns tableNames without the tables of migration
+// tools and extensions.
+func withoutFrameworkTables(tableNames []string) []string {
+	var kept []string
+	for _, tableName := range tableNames {
+		if !frameworkTables[strings.ToLower(tableName)] {
+			kept = append(kept, tableName)
+		}
+	}
+	return kept
+}
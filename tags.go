@@ -0,0 +1,147 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+var namingStrategies = []string{"snake", "camel", "pascal", "kebab"}
+
+// tagOrder is the order struct tags are written in after the gorm tag. Other
+// tags, e.g. from tag rules, follow in the order they were added.
+var tagOrder = []string{"json", "xml", "yaml", "bson", "mapstructure", "form", "query", "uri", "param", "binding", "validate", "swaggertype", "format", "enums", "example"}
+
+// namingFlag is a flag selecting the naming strategy of a struct tag. It can
+// be given without a value, e.g. -xml-tags, which selects snake case.
+type namingFlag string
+
+func (n *namingFlag) String() string { return string(*n) }
+
+func (n *namingFlag) Set(value string) error {
+	if value == "true" {
+		value = "snake"
+	} else if value == "false" {
+		value = ""
+	}
+	*n = namingFlag(value)
+	return nil
+}
+
+func (n *namingFlag) IsBoolFlag() bool { return true }
+
+// validNamingStrategy reports whether strategy is empty (tag disabled) or one
+// of the supported naming strategies.
+func validNamingStrategy(strategy string) bool {
+	if strategy == "" {
+		return true
+	}
+	for _, name := range namingStrategies {
+		if strategy == name {
+			return true
+		}
+	}
+	return false
+}
+
+// tagName converts a column name into a struct tag name using a naming
+// strategy, e.g. created_at -> createdAt for camel.
+func tagName(strategy, columnName string) string {
+	switch strategy {
+	case "camel":
+		name := camelCase(strings.ToLower(columnName))
+		if name == "" {
+			return name
+		}
+		return strings.ToLower(name[:1]) + name[1:]
+	case "pascal":
+		return camelCase(strings.ToLower(columnName))
+	case "kebab":
+		return strings.ReplaceAll(strings.ToLower(columnName), "_", "-")
+	default:
+		return strings.ToLower(columnName)
+	}
+}
+
+// serializationTags returns the json, xml, yaml, bson and mapstructure tags of
+// a column. Sensitive columns are excluded from every output format; the
+// mapstructure tag only decodes input and is kept.
+func serializationTags(config Config, tableName, columnName string) []Tag {
+	sensitive := matchColumn(config.Sensitive, tableName, columnName)
+
+	var tags []Tag
+	if sensitive {
+		tags = append(tags, Tag{Key: "json", Value: "-"})
+	}
+	for _, format := range []struct {
+		key      string
+		strategy string
+		redact   bool
+	}{
+		{"xml", config.XMLTags, true},
+		{"yaml", config.YAMLTags, true},
+		{"bson", config.BSONTags, true},
+		{"mapstructure", config.MapstructureTags, false},
+	} {
+		if format.strategy == "" {
+			continue
+		}
+		if sensitive && format.redact {
+			tags = append(tags, Tag{Key: format.key, Value: "-"})
+		} else {
+			tags = append(tags, Tag{Key: format.key, Value: tagName(format.strategy, columnName)})
+		}
+	}
+	return tags
+}
+
+// bindingTags returns the request binding tags of a column, e.g.
+// form:"name" binding:"required". Columns are required when they are NOT NULL
+// and the database does not fill them in; bool columns are never required
+// since gin rejects false for required fields. Sensitive columns cannot be
+// bound from a request.
+func bindingTags(config Config, tableName string, column Column) []Tag {
+	if len(config.BindingTags) == 0 {
+		return nil
+	}
+
+	sensitive := matchColumn(config.Sensitive, tableName, column.GormName)
+	var tags []Tag
+	for _, kind := range config.BindingTags {
+		if sensitive {
+			tags = append(tags, Tag{Key: kind, Value: "-"})
+		} else {
+			tags = append(tags, Tag{Key: kind, Value: column.GormName})
+		}
+	}
+
+	required := !column.Nullable && !column.PrimaryKey && !column.AutoIncrement && !column.HasDefault && column.Type != "bool"
+	if required && !sensitive {
+		tags = append(tags, Tag{Key: "binding", Value: "required"})
+	}
+	return tags
+}
+
+// typeComment returns the comment written after a field, e.g. varchar(255)
+// NOT NULL for a column of that type.
+func typeComment(databaseType string, nullable bool) string {
+	if nullable {
+		return databaseType + " NULL"
+	}
+	return databaseType + " NOT NULL"
+}
+
+// sortTags orders tags by tagOrder, so every field lists its tags in the same
+// order whichever option added them.
+func sortTags(tags []Tag) {
+	rank := func(key string) int {
+		for i, name := range tagOrder {
+			if key == name {
+				return i
+			}
+		}
+		return len(tagOrder)
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return rank(tags[i].Key) < rank(tags[j].Key)
+	})
+}
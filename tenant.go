@@ -0,0 +1,252 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+var tenantScopeTemplate = `package models
+
+import (
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+{{- range .Imports }}
+    "{{.}}"
+{{- end }}
+)
+
+// ScopeForTenant restricts a query to the rows of one tenant, e.g.
+// db.Scopes(models.ScopeForTenant(id)).Find(&users).
+func ScopeForTenant(tenantID {{.Type}}) func(*gorm.DB) *gorm.DB {
+    return func(db *gorm.DB) *gorm.DB {
+        return db.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "{{.Column}}"}, Value: tenantID})
+    }
+}
+`
+
+var tenantRepositoryTemplate = `package models
+
+import (
+{{- range .Imports }}
+    "{{.}}"
+{{- end }}
+{{- if and .Assign .PrimaryKeys }}
+    "reflect"
+{{- end }}
+{{- if or .Imports (and .Assign .PrimaryKeys) }}
+{{ end }}
+    "gorm.io/gorm"
+{{- if and .Assign .PrimaryKeys }}
+    "gorm.io/gorm/clause"
+{{- end }}
+)
+
+// {{.TableName}}TenantRepository {{if .ReadOnly}}reads{{else}}reads and writes{{end}} the {{.DBTableName}} rows of one tenant only.
+type {{.TableName}}TenantRepository struct {
+    db       *gorm.DB
+    tenantID {{.Type}}
+}
+
+// New{{.TableName}}TenantRepository returns the repository of a tenant on db,
+// which may also be a transaction.
+func New{{.TableName}}TenantRepository(db *gorm.DB, tenantID {{.Type}}) *{{.TableName}}TenantRepository {
+    return &{{.TableName}}TenantRepository{db: db, tenantID: tenantID}
+}
+
+// WithTx returns the repository of the same tenant in the transaction tx, e.g.
+// within db.Transaction(func(tx *gorm.DB) error { ... }).
+func (r *{{.TableName}}TenantRepository) WithTx(tx *gorm.DB) *{{.TableName}}TenantRepository {
+    return &{{.TableName}}TenantRepository{db: tx, tenantID: r.tenantID}
+}
+
+// DB returns a query on the {{.DBTableName}} table scoped to the tenant.
+func (r *{{.TableName}}TenantRepository) DB() *gorm.DB {
+    return r.db.Model(&{{.TableName}}{}).Scopes(ScopeForTenant(r.tenantID))
+}
+
+func (r *{{.TableName}}TenantRepository) Find(conds ...interface{}) ([]{{.TableName}}, error) {
+    var models []{{.TableName}}
+    err := r.DB().Find(&models, conds...).Error
+    return models, err
+}
+
+func (r *{{.TableName}}TenantRepository) First(conds ...interface{}) (*{{.TableName}}, error) {
+    var model {{.TableName}}
+    if err := r.DB().First(&model, conds...).Error; err != nil {
+        return nil, err
+    }
+    return &model, nil
+}
+{{- if .Assign }}
+
+// Create inserts the model into the tenant.
+func (r *{{.TableName}}TenantRepository) Create(model *{{.TableName}}) error {
+    {{.Assign}}
+    return r.db.Create(model).Error
+}
+
+// Save updates the model within the tenant, or inserts it when its primary key
+// is zero. It returns gorm.ErrRecordNotFound when the tenant has no row with
+// the primary key, so the rows of other tenants are never written.
+func (r *{{.TableName}}TenantRepository) Save(model *{{.TableName}}) error {
+    {{.Assign}}
+{{- if .PrimaryKeys }}
+    if {{range $i, $key := .PrimaryKeys}}{{if $i}} || {{end}}reflect.ValueOf(model.{{.Name}}).IsZero(){{end}} {
+        return r.db.Create(model).Error
+    }
+    result := r.db.Model(model).Scopes(ScopeForTenant(r.tenantID)).
+{{- range .PrimaryKeys }}
+        Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "{{.GormName}}"}, Value: model.{{.Name}}}).
+{{- end }}
+        Select("*").Omit(clause.Associations).
+        Updates(model)
+    if result.Error == nil && result.RowsAffected == 0 {
+        return gorm.ErrRecordNotFound
+    }
+    return result.Error
+{{- else }}
+    // Without a primary key a row cannot be updated
+    return r.db.Create(model).Error
+{{- end }}
+}
+{{- end }}
+{{- if not .ReadOnly }}
+
+// Delete deletes the model if it belongs to the tenant.
+func (r *{{.TableName}}TenantRepository) Delete(model *{{.TableName}}) error {
+    return r.db.Scopes(ScopeForTenant(r.tenantID)).Delete(model).Error
+}
+{{- end }}
+{{- with .SoftDelete }}
+
+// Restore undeletes the soft-deleted model if it belongs to the tenant.
+func (r *{{$.TableName}}TenantRepository) Restore(model *{{$.TableName}}) error {
+    return r.db.Unscoped().Model(model).Scopes(ScopeForTenant(r.tenantID)).Update("{{.}}", nil).Error
+}
+{{- end }}
+`
+
+// TenantScope is the data of the generated tenant.go file.
+type TenantScope struct {
+	Column  string
+	Type    string
+	Imports []string
+}
+
+// TenantRepository is the data of a generated <Model>TenantRepository.go file.
+type TenantRepository struct {
+	TableName   string
+	DBTableName string
+	Type        string
+	Imports     []string
+	// Assign sets the tenant column of a model to the repository's tenant,
+	// and is empty when the field type does not allow it
+	Assign string
+	// SoftDelete is the soft delete column, if the table has one
+	SoftDelete string
+	// ReadOnly leaves out the write methods of read-only models
+	ReadOnly bool
+	// PrimaryKeys are the primary key columns Save updates the row of
+	PrimaryKeys []Column
+}
+
+// tenantColumn returns the tenant column of a table, if it has one.
+func tenantColumn(config Config, table Table) (Column, bool) {
+	if config.TenantColumn == "" {
+		return Column{}, false
+	}
+	for _, column := range table.Columns {
+		if column.GormName == config.TenantColumn {
+			return column, true
+		}
+	}
+	return Column{}, false
+}
+
+// tenantIDType returns the type of tenant IDs for a tenant column, and the
+// import it requires, if any.
+func tenantIDType(column Column) (string, string) {
+	valueType, ok := queryValueType(column.Type)
+	if !ok {
+		return "interface{}", ""
+	}
+	if strings.HasPrefix(valueType, "time.") {
+		return valueType, "time"
+	}
+	return valueType, ""
+}
+
+// buildTenantRepository derives the tenant repository of a table.
+func buildTenantRepository(config Config, table Table, column Column) TenantRepository {
+	valueType, importPath := tenantIDType(column)
+	repository := TenantRepository{
+		TableName:   table.TableName,
+		DBTableName: table.DBTableName,
+		Type:        valueType,
+		ReadOnly:    table.ReadOnly,
+	}
+	if importPath != "" {
+		repository.Imports = []string{importPath}
+	}
+	if table.ReadOnly {
+		return repository
+	}
+	if config.SoftDelete {
+		if softDelete, ok := softDeleteColumn(config, table); ok {
+			repository.SoftDelete = softDelete.GormName
+		}
+	}
+	for _, primaryKey := range table.Columns {
+		if primaryKey.PrimaryKey {
+			repository.PrimaryKeys = append(repository.PrimaryKeys, primaryKey)
+		}
+	}
+	switch column.Type {
+	case valueType:
+		repository.Assign = "model." + column.Name + " = r.tenantID"
+	case "*" + valueType:
+		repository.Assign = "tenantID := r.tenantID\n    model." + column.Name + " = &tenantID"
+	}
+	return repository
+}
+
+// writeTenantFiles writes the ScopeForTenant helper when a generated table has
+// the tenant column, and the tenant and cached repositories when enabled.
+func writeTenantFiles(config Config, tables []Table, destPath string, report *Report) {
+	var scope *TenantScope
+	cached := false
+	for _, table := range tables {
+		column, ok := tenantColumn(config, table)
+		if !ok {
+			continue
+		}
+
+		// Tables disagreeing on the type of tenant IDs share an untyped scope
+		valueType, importPath := tenantIDType(column)
+		if scope == nil {
+			scope = &TenantScope{Column: config.TenantColumn, Type: valueType}
+			if importPath != "" {
+				scope.Imports = []string{importPath}
+			}
+		} else if scope.Type != valueType {
+			scope.Type, scope.Imports = "interface{}", nil
+		}
+
+		if config.TenantRepositories {
+			repository := buildTenantRepository(config, table, column)
+			path := fmt.Sprintf("%s/%sTenantRepository.go", destPath, table.TableName)
+			report.addTableFile(table.DBTableName, path, writeTemplate(tenantRepositoryTemplate, path, repository))
+			if config.CacheRepositories && writeCachedRepository(table, repository, destPath, report) {
+				cached = true
+			}
+		}
+	}
+	if scope != nil {
+		path := fmt.Sprintf("%s/tenant.go", destPath)
+		report.addFile(path, writeTemplate(tenantScopeTemplate, path, scope))
+	}
+	if cached {
+		path := fmt.Sprintf("%s/cache.go", destPath)
+		report.addFile(path, writeTemplate(cacheTemplate, path, nil))
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"database/sql"
+	"regexp"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+var tidbAutoRandomPattern = regexp.MustCompile(`PK_AUTO_RANDOM_BITS=(\d+)`)
+
+// tidbFeatureComment matches the executable comments SHOW CREATE TABLE wraps
+// TiDB's own syntax in, e.g. /*T![clustered_index] CLUSTERED */, whose
+// feature IDs vary between versions
+var tidbFeatureComment = regexp.MustCompile(`/\*T!(\[\w+\])? *(.*?) *\*/`)
+
+// tidbAutoRandomBase is the AUTO_RANDOM_BASE table option, which like
+// AUTO_INCREMENT holds the next id of the existing table
+var tidbAutoRandomBase = regexp.MustCompile(` AUTO_RANDOM_BASE=\d+`)
+
+// tidbCreateTable normalizes a CREATE TABLE statement of TiDB, unwrapping its
+// feature comments into the plain TiDB syntax, e.g. PRIMARY KEY (id)
+// CLUSTERED, so the statement hashes the same across versions, and dropping
+// AUTO_RANDOM_BASE.
+func tidbCreateTable(statement string) string {
+	statement = tidbFeatureComment.ReplaceAllString(statement, "$2")
+	return tidbAutoRandomBase.ReplaceAllString(statement, "")
+}
+
+// tidbAutoRandomBits returns the shard bits of a table whose primary key is
+// declared AUTO_RANDOM, or 0 when it is not. TiDB only exposes this through
+// the TIDB_ROW_ID_SHARDING_INFO column of INFORMATION_SCHEMA.TABLES.
+func tidbAutoRandomBits(db *gorm.DB, tableName string) (int, error) {
+	var shardingInfo sql.NullString
+	err := db.Raw("SELECT TIDB_ROW_ID_SHARDING_INFO FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?", tableName).Scan(&shardingInfo).Error
+	if err != nil {
+		return 0, err
+	}
+
+	match := tidbAutoRandomPattern.FindStringSubmatch(shardingInfo.String)
+	if match == nil {
+		return 0, nil
+	}
+	return strconv.Atoi(match[1])
+}
+
+// tidbAutoRandomOptions returns the gorm options for an AUTO_RANDOM primary
+// key. GORM cannot express AUTO_RANDOM natively, so it is carried in the type
+// tag instead of autoIncrement, which would make AutoMigrate emit an
+// AUTO_INCREMENT column that TiDB rejects alongside AUTO_RANDOM.
+func tidbAutoRandomOptions(columnType gorm.ColumnType, shardBits int) []string {
+	databaseType, ok := columnType.ColumnType()
+	if !ok {
+		databaseType = columnType.DatabaseTypeName()
+	}
+	return []string{"primaryKey", "type:" + databaseType + " AUTO_RANDOM(" + strconv.Itoa(shardBits) + ")"}
+}
@@ -0,0 +1,44 @@
+package main
+
+import "testing"
+
+func TestTidbCreateTable(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		want      string
+	}{
+		{
+			name:      "plain",
+			statement: "CREATE TABLE `users` (\n  `id` bigint NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB",
+			want:      "CREATE TABLE `users` (\n  `id` bigint NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB",
+		},
+		{
+			name:      "clustered index",
+			statement: "CREATE TABLE `users` (\n  `id` bigint NOT NULL,\n  PRIMARY KEY (`id`) /*T![clustered_index] CLUSTERED */\n)",
+			want:      "CREATE TABLE `users` (\n  `id` bigint NOT NULL,\n  PRIMARY KEY (`id`) CLUSTERED\n)",
+		},
+		{
+			name:      "comment without feature id",
+			statement: "CREATE TABLE `users` (\n  `id` bigint NOT NULL /*T! AUTO_RANDOM(5) */\n)",
+			want:      "CREATE TABLE `users` (\n  `id` bigint NOT NULL AUTO_RANDOM(5)\n)",
+		},
+		{
+			name:      "auto random base",
+			statement: "CREATE TABLE `users` (\n  `id` bigint NOT NULL /*T![auto_rand] AUTO_RANDOM(5) */\n) ENGINE=InnoDB /*T![auto_rand_base] AUTO_RANDOM_BASE=39001 */",
+			want:      "CREATE TABLE `users` (\n  `id` bigint NOT NULL AUTO_RANDOM(5)\n) ENGINE=InnoDB",
+		},
+		{
+			name:      "several comments",
+			statement: "PRIMARY KEY (`id`) /*T![clustered_index] NONCLUSTERED */,\n  KEY `idx` (`a`) /*T![global_index] GLOBAL */",
+			want:      "PRIMARY KEY (`id`) NONCLUSTERED,\n  KEY `idx` (`a`) GLOBAL",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := tidbCreateTable(test.statement); got != test.want {
+				t.Errorf("tidbCreateTable() =\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// timeDSNParams returns the DSN parameters matching a time mode, in the
+// syntax of the driver's DSN.
+func timeDSNParams(driver, mode string) string {
+	switch driver {
+	case "mysql", "tidb":
+		switch mode {
+		case "utc":
+			return "parseTime=true&loc=UTC"
+		case "local":
+			return "parseTime=true&loc=Local"
+		}
+	case "postgres", "cockroach":
+		if mode == "utc" {
+			return "TimeZone=UTC"
+		}
+	}
+	return ""
+}
+
+// timeColumnType maps the time.Time parts of a Go type to string in the
+// string time mode, e.g. *time.Time -> *string.
+func timeColumnType(goType, importPath, mode string) (string, string) {
+	if mode != "string" || !strings.Contains(goType, "time.Time") {
+		return goType, importPath
+	}
+	return strings.ReplaceAll(goType, "time.Time", "string"), ""
+}
+
+// timeComment documents how a model's time columns are read, and the DSN
+// parameters the application must connect with.
+func timeComment(driver, mode string) string {
+	var comment string
+	switch mode {
+	case "local":
+		comment = "Time columns are read as time.Time in local time"
+	case "string":
+		comment = "Time columns are read as strings, as formatted by the database"
+	default:
+		comment = "Time columns are read as time.Time in UTC"
+	}
+	if params := timeDSNParams(driver, mode); params != "" {
+		return comment + "; connect with " + params + "."
+	}
+	if mode == "string" && (driver == "mysql" || driver == "tidb") {
+		return comment + "; connect without parseTime."
+	}
+	return comment + "."
+}
+
+// timePrecisionOption returns the gorm option preserving the fractional
+// second precision of a datetime or timestamp column, e.g. precision:6, so
+// AutoMigrate recreates it. Columns mapped to string get the full column type
+// instead, e.g. type:datetime(6). Postgres columns at its default precision
+// of 6 need no option.
+func timePrecisionOption(driver string, columnType gorm.ColumnType, goType string) string {
+	databaseType := strings.ToLower(columnType.DatabaseTypeName())
+	if !strings.HasPrefix(databaseType, "datetime") && !strings.HasPrefix(databaseType, "timestamp") && !strings.HasPrefix(databaseType, "time") {
+		return ""
+	}
+	precision, _, ok := columnType.DecimalSize()
+	if !ok || precision <= 0 {
+		return ""
+	}
+	if (driver == "postgres" || driver == "cockroach") && precision >= 6 {
+		return ""
+	}
+
+	if strings.Contains(goType, "time.Time") {
+		return fmt.Sprintf("precision:%d", precision)
+	}
+	if fullType, ok := columnType.ColumnType(); ok && strings.Contains(fullType, "(") {
+		return "type:" + fullType
+	}
+	return fmt.Sprintf("type:%s(%d)", databaseType, precision)
+}
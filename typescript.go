@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var typeScriptTemplate = `
+{{- if .Money -}}
+export interface Money {
+  amount: string;
+  currency: string;
+}
+
+{{ end -}}
+{{- range .Enums -}}
+export type {{.Name}} = {{.Union}};
+
+{{ end -}}
+{{- range .Interfaces -}}
+export interface {{.Name}} {
+{{- range .Fields }}
+  {{.Name}}{{if .Optional}}?{{end}}: {{.Type}};
+{{- end }}
+}
+
+{{ end -}}
+`
+
+// writeTypeScript writes the TypeScript interfaces of the models to path.
+func writeTypeScript(path string, tables []Table) string {
+	content := bytes.TrimRight(renderTemplate(typeScriptTemplate, buildTypeScript(tables)), "\n")
+	return writeFile(path, append(content, '\n'))
+}
+
+// TypeScript is the data of the generated TypeScript file.
+type TypeScript struct {
+	Money      bool
+	Enums      []TypeScriptEnum
+	Interfaces []TypeScriptInterface
+}
+
+// TypeScriptEnum is a union type of the values of an enum type.
+type TypeScriptEnum struct {
+	Name  string
+	Union string
+}
+
+// TypeScriptInterface is the interface of the JSON encoding of a model.
+type TypeScriptInterface struct {
+	Name   string
+	Fields []TypeScriptField
+}
+
+// TypeScriptField is a property of a TypeScript interface.
+type TypeScriptField struct {
+	Name     string
+	Type     string
+	Optional bool
+}
+
+// buildTypeScript derives TypeScript interfaces matching how encoding/json
+// encodes the models: properties are named by json tags, fields without one
+// keep their Go name, and fields tagged json:"-" are left out.
+func buildTypeScript(tables []Table) TypeScript {
+	var typeScript TypeScript
+	enums := map[string]bool{}
+	for _, table := range tables {
+		for _, enum := range table.Enums {
+			var values []string
+			for _, constant := range enum.Constants {
+				values = append(values, strconv.Quote(constant.Value))
+			}
+			typeScript.Enums = append(typeScript.Enums, TypeScriptEnum{Name: enum.Name, Union: strings.Join(values, " | ")})
+			enums[enum.Name] = true
+		}
+	}
+
+	for _, table := range tables {
+		tsInterface := TypeScriptInterface{Name: table.TableName}
+		for _, column := range table.Columns {
+			name, optional, ok := jsonName(column)
+			if !ok {
+				continue
+			}
+			tsInterface.Fields = append(tsInterface.Fields, TypeScriptField{Name: name, Type: typeScriptType(column.Type, enums), Optional: optional})
+		}
+		for _, money := range table.MoneyFields {
+			tsInterface.Fields = append(tsInterface.Fields, TypeScriptField{Name: money.Name, Type: "Money"})
+			typeScript.Money = true
+		}
+		for _, association := range table.Associations {
+			tsInterface.Fields = append(tsInterface.Fields, TypeScriptField{Name: association.Name, Type: typeScriptType(association.Type, enums)})
+		}
+		typeScript.Interfaces = append(typeScript.Interfaces, tsInterface)
+	}
+	return typeScript
+}
+
+// jsonName returns the JSON property of a column's field and whether it is
+// omitted when empty. ok is false when the field is not encoded.
+func jsonName(column Column) (string, bool, bool) {
+	for _, tag := range column.Tags {
+		if tag.Key != "json" {
+			continue
+		}
+		parts := strings.Split(tag.Value, ",")
+		if parts[0] == "-" && len(parts) == 1 {
+			return "", false, false
+		}
+		name := parts[0]
+		if name == "" {
+			name = column.Name
+		}
+		return name, containsString(parts[1:], "omitempty"), true
+	}
+	return column.Name, false, true
+}
+
+// typeScriptType returns the TypeScript type of the JSON encoding of a Go
+// type. Pointers, slices and maps encode nil as null.
+func typeScriptType(goType string, enums map[string]bool) string {
+	switch {
+	case strings.HasPrefix(goType, "*"):
+		return typeScriptType(strings.TrimPrefix(goType, "*"), enums) + " | null"
+	case goType == "[]byte" || goType == "[]uint8":
+		// Encoded as base64
+		return "string | null"
+	case strings.HasPrefix(goType, "[]"):
+		elemType := typeScriptType(strings.TrimPrefix(goType, "[]"), enums)
+		if strings.Contains(elemType, " ") {
+			elemType = "(" + elemType + ")"
+		}
+		return elemType + "[] | null"
+	case strings.HasPrefix(goType, "map["):
+		keyType, valueType, _ := strings.Cut(strings.TrimPrefix(goType, "map["), "]")
+		return fmt.Sprintf("Record<%s, %s> | null", typeScriptType(keyType, enums), typeScriptType(valueType, enums))
+	case enums[goType]:
+		return goType
+	}
+
+	nullTypes := map[string]struct{ field, valueType string }{
+		"sql.NullString":  {"String", "string"},
+		"sql.NullInt64":   {"Int64", "number"},
+		"sql.NullInt32":   {"Int32", "number"},
+		"sql.NullInt16":   {"Int16", "number"},
+		"sql.NullFloat64": {"Float64", "number"},
+		"sql.NullBool":    {"Bool", "boolean"},
+		"sql.NullTime":    {"Time", "string"},
+	}
+	if null, ok := nullTypes[goType]; ok {
+		// database/sql null types have no JSON methods and encode as structs
+		return fmt.Sprintf("{ %s: %s; Valid: boolean }", null.field, null.valueType)
+	}
+
+	switch goType {
+	case "string", "EncryptedString", "time.Time", "uuid.UUID", "netip.Addr", "decimal.Decimal":
+		return "string"
+	case "null.String", "null.Time", "gorm.DeletedAt":
+		return "string | null"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+		return "number"
+	case "null.Int", "null.Float":
+		return "number | null"
+	case "bool":
+		return "boolean"
+	case "null.Bool":
+		return "boolean | null"
+	case "json.RawMessage":
+		return "unknown"
+	}
+	if !strings.Contains(goType, ".") && goType != "" && goType[0] >= 'A' && goType[0] <= 'Z' {
+		// Another generated model
+		return goType
+	}
+	return "unknown"
+}
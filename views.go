@@ -0,0 +1,23 @@
+package main
+
+import "gorm.io/gorm"
+
+// isView reports whether a table of the current database or schema is a
+// view, including materialized views.
+func isView(db *gorm.DB, driver, tableName string) (bool, error) {
+	var view bool
+	var err error
+	switch driver {
+	case "postgres", "cockroach":
+		// information_schema.tables leaves out materialized views
+		err = db.Raw(`SELECT c.relkind IN ('v', 'm')
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = current_schema() AND c.relname = ?`, tableName).Scan(&view).Error
+	case "clickhouse":
+		err = db.Raw("SELECT engine IN ('View', 'MaterializedView', 'LiveView', 'WindowView') FROM system.tables WHERE database = currentDatabase() AND name = ?", tableName).Scan(&view).Error
+	default:
+		err = db.Raw("SELECT table_type IN ('VIEW', 'SYSTEM VIEW') FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?", tableName).Scan(&view).Error
+	}
+	return view, err
+}